@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/notify"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// subscriberSpec describes a webhook subscriber.
+type subscriberSpec struct {
+	Name       string   `yaml:"name"`       // Identifies the subscriber in logs and metrics
+	URL        string   `yaml:"url"`        // Endpoint receiving notifications
+	SecretEnv  string   `yaml:"secretEnv"`  // Env var holding the HMAC signing secret
+	Events     []string `yaml:"events"`     // ADDED, MODIFIED and/or DELETED
+	Templates  []string `yaml:"templates"`  // Claim templates to notify about
+	Categories []string `yaml:"categories"` // Claim categories to notify about
+	Statuses   []string `yaml:"statuses"`   // Claim statuses to notify about
+	Fields     []string `yaml:"fields"`     // Fields whose modification is notified
+}
+
+// notifyFile is the format of the NOTIFY_CONFIG file.
+type notifyFile struct {
+	MaxAttempts    int              `yaml:"maxAttempts"`
+	InitialBackoff string           `yaml:"initialBackoff"`
+	MaxBackoff     string           `yaml:"maxBackoff"`
+	Timeout        string           `yaml:"timeout"`
+	DeadLetterFile string           `yaml:"deadLetterFile"`
+	Subscribers    []subscriberSpec `yaml:"subscribers"`
+}
+
+// loadDispatcher creates the webhook dispatcher configured by the
+// NOTIFY_CONFIG file, or returns nil when notifications are not configured.
+func loadDispatcher(logger *slog.Logger) (*notify.Dispatcher, error) {
+	path := os.Getenv("NOTIFY_CONFIG")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading NOTIFY_CONFIG: %w", err)
+	}
+	var file notifyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing NOTIFY_CONFIG %s: %w", path, err)
+	}
+	if len(file.Subscribers) == 0 {
+		return nil, fmt.Errorf("NOTIFY_CONFIG %s defines no subscribers", path)
+	}
+
+	cfg := notify.Config{
+		MaxAttempts:    file.MaxAttempts,
+		DeadLetterFile: file.DeadLetterFile,
+		Logger:         logger,
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"initialBackoff", file.InitialBackoff, &cfg.InitialBackoff},
+		{"maxBackoff", file.MaxBackoff, &cfg.MaxBackoff},
+		{"timeout", file.Timeout, &cfg.Timeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if *d.dst, err = time.ParseDuration(d.value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q in NOTIFY_CONFIG: %w", d.name, d.value, err)
+		}
+	}
+
+	for i, spec := range file.Subscribers {
+		sub := notify.Subscriber{
+			Name:       spec.Name,
+			URL:        spec.URL,
+			Templates:  spec.Templates,
+			Categories: spec.Categories,
+			Statuses:   spec.Statuses,
+			Fields:     spec.Fields,
+		}
+		if spec.SecretEnv != "" {
+			if sub.Secret = os.Getenv(spec.SecretEnv); sub.Secret == "" {
+				return nil, fmt.Errorf("subscriber %d: secret variable %s is not set", i, spec.SecretEnv)
+			}
+		}
+		for _, e := range spec.Events {
+			sub.Events = append(sub.Events, registry.ChangeType(strings.ToUpper(e)))
+		}
+		cfg.Subscribers = append(cfg.Subscribers, sub)
+	}
+
+	return notify.NewDispatcher(cfg)
+}
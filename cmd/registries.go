@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+	"gopkg.in/yaml.v3"
+)
+
+// registrySpec describes a single registry to sync.
+type registrySpec struct {
+	Name     string `yaml:"name"`     // Registry name shown as the entries' origin
+	Source   string `yaml:"source"`   // github (default), git or file:///path
+	Repo     string `yaml:"repo"`     // GitHub repo slug or git remote URL
+	Path     string `yaml:"path"`     // Path to registry file in repo
+	Branch   string `yaml:"branch"`   // Git branch
+	Interval string `yaml:"interval"` // Polling interval override
+	TokenEnv string `yaml:"tokenEnv"` // Env var holding the token; defaults to GITHUB_TOKEN
+
+	token string // token resolved from the environment
+}
+
+// registriesFile is the format of the REGISTRY_CONFIG file.
+type registriesFile struct {
+	ConflictPolicy string         `yaml:"conflictPolicy"`
+	Registries     []registrySpec `yaml:"registries"`
+}
+
+// loadRegistrySpecs resolves the registries to sync and the conflict policy.
+// Sources are, in order of precedence: the REGISTRY_CONFIG file, indexed
+// REGISTRY_<n>_* variables, and the single-registry REGISTRY_* variables.
+// REGISTRY_CONFLICT_POLICY overrides the policy from the file.
+func loadRegistrySpecs() ([]registrySpec, string, error) {
+	var (
+		specs  []registrySpec
+		policy string
+	)
+
+	switch {
+	case os.Getenv("REGISTRY_CONFIG") != "":
+		path := os.Getenv("REGISTRY_CONFIG")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading REGISTRY_CONFIG: %w", err)
+		}
+		var file registriesFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, "", fmt.Errorf("parsing REGISTRY_CONFIG %s: %w", path, err)
+		}
+		if len(file.Registries) == 0 {
+			return nil, "", fmt.Errorf("REGISTRY_CONFIG %s defines no registries", path)
+		}
+		specs, policy = file.Registries, file.ConflictPolicy
+
+	case os.Getenv("REGISTRY_0_REPO") != "" || os.Getenv("REGISTRY_0_SOURCE") != "":
+		for i := 0; ; i++ {
+			prefix := "REGISTRY_" + strconv.Itoa(i) + "_"
+			spec := registrySpec{
+				Name:     os.Getenv(prefix + "NAME"),
+				Source:   os.Getenv(prefix + "SOURCE"),
+				Repo:     os.Getenv(prefix + "REPO"),
+				Path:     os.Getenv(prefix + "PATH"),
+				Branch:   os.Getenv(prefix + "BRANCH"),
+				Interval: os.Getenv(prefix + "INTERVAL"),
+				token:    os.Getenv(prefix + "TOKEN"),
+			}
+			if spec.Repo == "" && spec.Source == "" {
+				break
+			}
+			specs = append(specs, spec)
+		}
+
+	default:
+		spec := registrySpec{
+			Source: os.Getenv("REGISTRY_SOURCE"),
+			Repo:   os.Getenv("REGISTRY_REPO"),
+			Path:   os.Getenv("REGISTRY_PATH"),
+			Branch: os.Getenv("REGISTRY_BRANCH"),
+		}
+		if spec.Repo == "" && !strings.HasPrefix(spec.Source, "file://") {
+			return nil, "", fmt.Errorf("REGISTRY_REPO environment variable is required")
+		}
+		specs = []registrySpec{spec}
+	}
+
+	if v := os.Getenv("REGISTRY_CONFLICT_POLICY"); v != "" {
+		policy = v
+	}
+
+	names := map[string]bool{}
+	for i := range specs {
+		if specs[i].token == "" {
+			env := specs[i].TokenEnv
+			if env == "" {
+				env = "GITHUB_TOKEN"
+			}
+			specs[i].token = os.Getenv(env)
+		}
+		if specs[i].Repo == "" && !strings.HasPrefix(specs[i].Source, "file://") {
+			return nil, "", fmt.Errorf("registry %d: repo is required", i)
+		}
+		// Names default to the repo, as in the syncer, so registries
+		// sharing a repo need explicit names.
+		n := cmp.Or(specs[i].Name, specs[i].Repo, specs[i].Source)
+		if names[n] {
+			return nil, "", fmt.Errorf("registry %d: duplicate registry name %q (set a unique name)", i, n)
+		}
+		names[n] = true
+	}
+
+	return specs, policy, nil
+}
+
+// newSyncer creates the syncer for spec on top of the shared defaults.
+func (spec registrySpec) newSyncer(defaults isync.Config) (*isync.Syncer, error) {
+	cfg := defaults
+	cfg.Name = spec.Name
+	cfg.Repo = spec.Repo
+	cfg.Path = spec.Path
+	cfg.Branch = spec.Branch
+	cfg.Token = spec.token
+
+	if spec.Interval != "" {
+		d, err := time.ParseDuration(spec.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q for registry %s: %w", spec.Interval, spec.Repo, err)
+		}
+		cfg.Interval = d
+	}
+
+	// Apply the syncer defaults for path and branch before building sources.
+	if cfg.Path == "" {
+		cfg.Path = "claims/registry.yaml"
+	}
+	if cfg.Branch == "" {
+		cfg.Branch = "main"
+	}
+
+	source, err := resolveSource(spec.Source, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Source = source
+
+	if dir := os.Getenv("REGISTRY_CACHE_DIR"); dir != "" {
+		// One snapshot file per registry below the shared cache directory.
+		// The hash of the source keeps registries on the same repo apart.
+		name := cmp.Or(cfg.Name, cfg.Repo, spec.Source)
+		source := sha256.Sum256([]byte(strings.Join([]string{spec.Source, cfg.Repo, cfg.Branch, cfg.Path}, "\x00")))
+		cfg.CacheFile = filepath.Join(dir, fmt.Sprintf("%s-%x.json", cloneDirName.ReplaceAllString(name, "-"), source[:4]))
+	}
+
+	return isync.NewSyncer(cfg), nil
+}
+
+// cloneDirName matches characters not allowed in per-registry clone
+// directories and cache files.
+var cloneDirName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// resolveSource builds the registry source selected by spec.
+// A nil source selects the syncer's default GitHub raw backend.
+func resolveSource(spec string, cfg isync.Config) (isync.Source, error) {
+	switch {
+	case spec == "" || spec == "github":
+		return nil, nil
+	case spec == "git":
+		src := isync.NewGitSource(cfg)
+		if dir := os.Getenv("REGISTRY_CLONE_DIR"); dir != "" {
+			// One clone per registry below the shared clone directory.
+			name := cfg.Name
+			if name == "" {
+				name = cfg.Repo
+			}
+			src.Dir = filepath.Join(dir, cloneDirName.ReplaceAllString(name, "-"))
+		}
+		return src, nil
+	case strings.HasPrefix(spec, "file://"):
+		u, err := url.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry source %q: %w", spec, err)
+		}
+		// file://claims/registry.yaml parses "claims" as host; treat it as
+		// a relative path rather than rejecting it.
+		path := u.Host + u.Path
+		if path == "" {
+			return nil, fmt.Errorf("invalid registry source %q: missing file path", spec)
+		}
+		src := isync.NewFileSource(path)
+		src.Logger = cfg.Logger
+		return src, nil
+	default:
+		return nil, fmt.Errorf("unsupported registry source %q (expected github, git or file:///path)", spec)
+	}
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// registryEnv lists the variables read by loadRegistrySpecs; tests clear
+// them so the environment of the test run does not leak in.
+var registryEnv = []string{
+	"REGISTRY_CONFIG", "REGISTRY_CONFLICT_POLICY", "GITHUB_TOKEN",
+	"REGISTRY_SOURCE", "REGISTRY_REPO", "REGISTRY_PATH", "REGISTRY_BRANCH",
+	"REGISTRY_0_NAME", "REGISTRY_0_SOURCE", "REGISTRY_0_REPO", "REGISTRY_0_TOKEN",
+	"REGISTRY_1_NAME", "REGISTRY_1_SOURCE", "REGISTRY_1_REPO", "REGISTRY_1_TOKEN",
+}
+
+func TestLoadRegistrySpecs(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		config     string // REGISTRY_CONFIG contents; "-" points it at a missing file
+		want       []registrySpec
+		wantPolicy string
+		wantErr    string
+	}{
+		{
+			name: "single registry",
+			env: map[string]string{
+				"REGISTRY_REPO": "org/a", "REGISTRY_BRANCH": "dev", "REGISTRY_PATH": "r.yaml",
+				"GITHUB_TOKEN": "gh",
+			},
+			want: []registrySpec{{Repo: "org/a", Branch: "dev", Path: "r.yaml", token: "gh"}},
+		},
+		{
+			name: "single file registry needs no repo",
+			env:  map[string]string{"REGISTRY_SOURCE": "file:///srv/registry.yaml"},
+			want: []registrySpec{{Source: "file:///srv/registry.yaml"}},
+		},
+		{
+			name:    "single registry without repo",
+			env:     map[string]string{"REGISTRY_SOURCE": "git"},
+			wantErr: "REGISTRY_REPO environment variable is required",
+		},
+		{
+			name: "indexed registries take precedence and stop at the first gap",
+			env: map[string]string{
+				"REGISTRY_0_REPO": "org/a", "REGISTRY_0_TOKEN": "t0",
+				"REGISTRY_1_NAME": "local", "REGISTRY_1_SOURCE": "file:///srv/registry.yaml",
+				"REGISTRY_3_REPO":          "org/ignored",
+				"REGISTRY_REPO":            "org/single",
+				"REGISTRY_CONFLICT_POLICY": "last",
+				"GITHUB_TOKEN":             "gh",
+			},
+			want: []registrySpec{
+				{Repo: "org/a", token: "t0"},
+				{Name: "local", Source: "file:///srv/registry.yaml", token: "gh"},
+			},
+			wantPolicy: "last",
+		},
+		{
+			name: "indexed registry without repo",
+			env: map[string]string{
+				"REGISTRY_0_REPO": "org/a", "REGISTRY_1_SOURCE": "git",
+			},
+			wantErr: "registry 1: repo is required",
+		},
+		{
+			name: "config file takes precedence",
+			env: map[string]string{
+				"REGISTRY_0_REPO": "org/ignored", "REGISTRY_REPO": "org/ignored",
+				"GITHUB_TOKEN": "gh", "TEAM_TOKEN": "team",
+			},
+			config: `
+conflictPolicy: reject
+registries:
+  - name: harvester
+    repo: org/a
+    interval: 1m
+  - repo: org/b
+    tokenEnv: TEAM_TOKEN
+`,
+			want: []registrySpec{
+				{Name: "harvester", Repo: "org/a", Interval: "1m", token: "gh"},
+				{Repo: "org/b", TokenEnv: "TEAM_TOKEN", token: "team"},
+			},
+			wantPolicy: "reject",
+		},
+		{
+			name: "REGISTRY_CONFLICT_POLICY overrides the config file",
+			env:  map[string]string{"REGISTRY_CONFLICT_POLICY": "first"},
+			config: `
+conflictPolicy: reject
+registries:
+  - repo: org/a
+`,
+			want:       []registrySpec{{Repo: "org/a"}},
+			wantPolicy: "first",
+		},
+		{
+			name:    "missing config file",
+			config:  "-",
+			wantErr: "reading REGISTRY_CONFIG",
+		},
+		{
+			name:    "invalid config file",
+			config:  "registries: {",
+			wantErr: "parsing REGISTRY_CONFIG",
+		},
+		{
+			name:    "config file without registries",
+			config:  "conflictPolicy: first\n",
+			wantErr: "defines no registries",
+		},
+		{
+			name: "config registry without repo",
+			config: `
+registries:
+  - repo: org/a
+  - name: b
+`,
+			wantErr: "registry 1: repo is required",
+		},
+		{
+			name: "registries on one repo with distinct names",
+			config: `
+registries:
+  - {name: prod, repo: org/a, path: prod.yaml}
+  - {name: dev, repo: org/a, path: dev.yaml}
+`,
+			want: []registrySpec{
+				{Name: "prod", Repo: "org/a", Path: "prod.yaml"},
+				{Name: "dev", Repo: "org/a", Path: "dev.yaml"},
+			},
+		},
+		{
+			name: "duplicate name",
+			config: `
+registries:
+  - {name: a, repo: org/a}
+  - {name: a, repo: org/b}
+`,
+			wantErr: `registry 1: duplicate registry name "a"`,
+		},
+		{
+			name: "name defaults to the repo",
+			config: `
+registries:
+  - {repo: org/a, path: prod.yaml}
+  - {repo: org/a, path: dev.yaml}
+`,
+			wantErr: `registry 1: duplicate registry name "org/a"`,
+		},
+		{
+			name: "name clashing with another registry's repo",
+			config: `
+registries:
+  - {repo: org/a}
+  - {name: org/a, repo: org/b}
+`,
+			wantErr: `registry 1: duplicate registry name "org/a"`,
+		},
+		{
+			name: "name defaults to the source without a repo",
+			config: `
+registries:
+  - {source: "file:///srv/registry.yaml"}
+  - {source: "file:///srv/registry.yaml"}
+`,
+			wantErr: `registry 1: duplicate registry name "file:///srv/registry.yaml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range registryEnv {
+				t.Setenv(k, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			switch tt.config {
+			case "":
+			case "-":
+				t.Setenv("REGISTRY_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+			default:
+				path := filepath.Join(t.TempDir(), "registries.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+				t.Setenv("REGISTRY_CONFIG", path)
+			}
+
+			specs, policy, err := loadRegistrySpecs()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, specs)
+			assert.Equal(t, tt.wantPolicy, policy)
+		})
+	}
+}
+
+func TestRegistryCacheFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("REGISTRY_CACHE_DIR", dir)
+
+	// cacheFile is the documented naming scheme: the sanitized name and the
+	// first four bytes of the SHA-256 of source, repo, branch and path.
+	cacheFile := func(name string, parts ...string) string {
+		var data []byte
+		for i, p := range parts {
+			if i > 0 {
+				data = append(data, 0)
+			}
+			data = append(data, p...)
+		}
+		sum := sha256.Sum256(data)
+		return filepath.Join(dir, fmt.Sprintf("%s-%x.json", name, sum[:4]))
+	}
+
+	tests := []struct {
+		name string
+		spec registrySpec
+		want string
+	}{
+		{
+			name: "explicit name",
+			spec: registrySpec{Name: "team a/prod", Repo: "org/a", Branch: "dev", Path: "prod.yaml"},
+			want: cacheFile("team-a-prod", "", "org/a", "dev", "prod.yaml"),
+		},
+		{
+			name: "name defaults to the repo, branch and path to the syncer defaults",
+			spec: registrySpec{Source: "github", Repo: "org/a"},
+			want: cacheFile("org-a", "github", "org/a", "main", "claims/registry.yaml"),
+		},
+		{
+			name: "file source without repo",
+			spec: registrySpec{Source: "file:///srv/registry.yaml"},
+			want: cacheFile("file-srv-registry.yaml", "file:///srv/registry.yaml", "", "main", "claims/registry.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.spec.newSyncer(isync.Config{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Config().CacheFile)
+		})
+	}
+
+	// Registries sharing a name still get separate files per location.
+	prod, err := registrySpec{Name: "a", Repo: "org/a", Path: "prod.yaml"}.newSyncer(isync.Config{})
+	require.NoError(t, err)
+	dev, err := registrySpec{Name: "a", Repo: "org/a", Path: "dev.yaml"}.newSyncer(isync.Config{})
+	require.NoError(t, err)
+	assert.NotEqual(t, prod.Config().CacheFile, dev.Config().CacheFile)
+}
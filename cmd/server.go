@@ -3,15 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stuttgart-things/machinery-registry-api/internal/api"
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
 	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+	"github.com/stuttgart-things/machinery-registry-api/internal/tracing"
 )
 
 var serverCmd = &cobra.Command{
@@ -26,25 +31,31 @@ func init() {
 }
 
 func runServer(cmd *cobra.Command, args []string) error {
-	fmt.Println(logo)
-	fmt.Printf("Version:    %s\n", Version)
-	fmt.Printf("Commit:     %s\n", Commit)
-	fmt.Printf("Build Date: %s\n\n", Date)
+	logger, err := logging.FromEnv()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
 
-	// Resolve configuration from environment
-	repo := os.Getenv("REGISTRY_REPO")
-	if repo == "" {
-		return fmt.Errorf("REGISTRY_REPO environment variable is required")
+	// The banner is for humans; JSON logs stay machine-readable.
+	banner := os.Getenv("LOG_FORMAT") != "json"
+	if banner {
+		fmt.Println(logo)
+		fmt.Printf("Version:    %s\n", Version)
+		fmt.Printf("Commit:     %s\n", Commit)
+		fmt.Printf("Build Date: %s\n\n", Date)
 	}
+	logger.Info("starting server", "version", Version, "commit", Commit, "buildDate", Date)
 
-	regPath := os.Getenv("REGISTRY_PATH")
-	if regPath == "" {
-		regPath = "claims/registry.yaml"
+	// Resolve configuration from environment
+	specs, policyName, err := loadRegistrySpecs()
+	if err != nil {
+		return err
 	}
 
-	branch := os.Getenv("REGISTRY_BRANCH")
-	if branch == "" {
-		branch = "main"
+	policy, err := isync.ParseConflictPolicy(policyName)
+	if err != nil {
+		return err
 	}
 
 	interval := 60 * time.Second
@@ -56,35 +67,172 @@ func runServer(cmd *cobra.Command, args []string) error {
 		interval = d
 	}
 
-	token := os.Getenv("GITHUB_TOKEN")
+	var staleAfter time.Duration
+	if v := os.Getenv("SYNC_STALE_AFTER"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid SYNC_STALE_AFTER %q: %w", v, err)
+		}
+		staleAfter = d
+	}
+
+	var maxBackoff time.Duration
+	if v := os.Getenv("SYNC_MAX_BACKOFF"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid SYNC_MAX_BACKOFF %q: %w", v, err)
+		}
+		maxBackoff = d
+	}
 
-	fmt.Printf("Registry:   %s/%s@%s\n", repo, regPath, branch)
-	fmt.Printf("Sync:       every %s\n", interval)
+	manifestTTL := 5 * time.Minute
+	if v := os.Getenv("MANIFEST_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid MANIFEST_TTL %q: %w", v, err)
+		}
+		manifestTTL = d
+	}
 
-	// Create and run initial sync
-	syncer := isync.NewSyncer(isync.Config{
-		Repo:     repo,
-		Path:     regPath,
-		Branch:   branch,
-		Token:    token,
-		Interval: interval,
-	})
+	var manifestCacheSize int
+	if v := os.Getenv("MANIFEST_CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid MANIFEST_CACHE_SIZE %q: must be a positive integer", v)
+		}
+		manifestCacheSize = n
+	}
+
+	csvColumns, err := registry.ParseFields(os.Getenv("CSV_COLUMNS"))
+	if err != nil {
+		return fmt.Errorf("invalid CSV_COLUMNS: %w", err)
+	}
+
+	var crawlParams bool
+	if v := os.Getenv("PARAM_CRAWL"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid PARAM_CRAWL %q: %w", v, err)
+		}
+		crawlParams = b
+	}
+
+	var crawlInterval time.Duration
+	if v := os.Getenv("PARAM_CRAWL_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid PARAM_CRAWL_INTERVAL %q: %w", v, err)
+		}
+		crawlInterval = d
+	}
+
+	var historySize int
+	if v := os.Getenv("CHANGE_HISTORY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid CHANGE_HISTORY %q: must be a positive integer", v)
+		}
+		historySize = n
+	}
+
+	var eventHistory int
+	if v := os.Getenv("WATCH_HISTORY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid WATCH_HISTORY %q: must be a positive integer", v)
+		}
+		eventHistory = n
+	}
+
+	syncers := make([]*isync.Syncer, 0, len(specs))
+	for _, spec := range specs {
+		syncer, err := spec.newSyncer(isync.Config{
+			Interval:   interval,
+			StaleAfter: staleAfter,
+			MaxBackoff: maxBackoff,
+			Logger:     logger,
+		})
+		if err != nil {
+			return err
+		}
+		cfg := syncer.Config()
+		logger.Info("registry configured", logging.KeyRegistry, cfg.Name,
+			"source", cfg.Source.String(), "interval", cfg.Interval.String())
+		syncers = append(syncers, syncer)
+	}
+	if len(syncers) > 1 {
+		logger.Info("federating registries", "registries", len(syncers), "policy", string(policy))
+	}
 
+	// Configure tracing before the first fetch so it is traced as well
 	ctx := context.Background()
-	if err := syncer.InitialSync(ctx); err != nil {
+	shutdownTracing, err := tracing.Setup(ctx, Version)
+	if err != nil {
+		return fmt.Errorf("tracing setup failed: %w", err)
+	}
+	if tracing.Enabled() {
+		logger.Info("tracing enabled")
+	}
+
+	// Outbound webhook notifications
+	dispatcher, err := loadDispatcher(logger)
+	if err != nil {
+		return err
+	}
+	var onChange func(isync.Changeset)
+	if dispatcher != nil {
+		dispatcher.Start(ctx)
+		onChange = dispatcher.Notify
+		logger.Info("webhook notifications enabled")
+	}
+
+	// Claim manifests, served by the API and optionally crawled for parameters
+	manifests := manifest.NewFetcher(manifest.Config{
+		Ref:     os.Getenv("MANIFEST_REF"),
+		Token:   os.Getenv("GITHUB_TOKEN"),
+		TTL:     manifestTTL,
+		Entries: manifestCacheSize,
+	})
+	var crawler *isync.ParamCrawler
+	if crawlParams {
+		crawler = isync.NewParamCrawler(isync.ParamCrawlerConfig{
+			Fetcher:  manifests,
+			Interval: crawlInterval,
+			Logger:   logger,
+		})
+		logger.Info("parameter crawling enabled")
+	}
+
+	// Create and run initial sync
+	registries := isync.NewFederation(isync.FederationConfig{
+		ConflictPolicy: policy,
+		Logger:         logger,
+		EventHistory:   eventHistory,
+		HistorySize:    historySize,
+		OnChange:       onChange,
+		Crawler:        crawler,
+	}, syncers...)
+
+	if err := registries.InitialSync(ctx); err != nil {
 		return fmt.Errorf("initial sync failed: %w", err)
 	}
 
 	// Start background sync
-	syncer.Start(ctx)
+	registries.Start(ctx)
 
 	// Create and start API server
-	server := api.NewServer(syncer)
+	server := api.NewServer(registries, api.Config{
+		WebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		SyncToken:     os.Getenv("SYNC_TOKEN"),
+		Logger:        logger,
+		Manifests:     manifests,
+		CSVColumns:    csvColumns,
+	})
 
 	go func() {
 		if err := server.Start(); err != nil {
 			if err.Error() != "http: Server closed" {
-				log.Printf("Server error: %v", err)
+				logger.Error("server error", logging.Err(err))
 			}
 		}
 	}()
@@ -94,32 +242,64 @@ func runServer(cmd *cobra.Command, args []string) error {
 		port = "8080"
 	}
 
-	fmt.Printf("\nAPI server listening on http://localhost:%s\n", port)
-	fmt.Println("\nAvailable endpoints:")
-	fmt.Println("  GET  /health                     - Health check")
-	fmt.Println("  GET  /version                    - Version info")
-	fmt.Println("  GET  /api/v1/claims              - List claims")
-	fmt.Println("  GET  /api/v1/claims/{name}       - Get claim by name")
-	fmt.Println("  GET  /openapi.yaml               - OpenAPI spec")
-	fmt.Println("  GET  /docs                       - API docs")
+	logger.Info("API server listening", "port", port)
+	if banner {
+		printEndpoints(port)
+	}
 
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	sig := <-sigChan
-	fmt.Printf("\nReceived signal: %v\n", sig)
+	logger.Info("received signal", "signal", sig.String())
 
-	// Graceful shutdown
+	// Graceful shutdown: drain HTTP requests first, then stop syncing so no
+	// new notifications are queued, then deliver the queued ones. Delivery
+	// and trace export get their own budgets so a slow drain does not cut
+	// them short.
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	if err := server.Stop(shutdownCtx); err != nil {
+		logger.Error("shutdown failed", logging.Err(err))
+	}
 
-	syncer.Stop()
+	registries.Stop()
+	if dispatcher != nil {
+		notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		dispatcher.Stop(notifyCtx)
+	}
 
-	if err := server.Stop(shutdownCtx); err != nil {
-		log.Printf("Error during shutdown: %v", err)
+	tracingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		logger.Error("flushing traces failed", logging.Err(err))
 	}
 
-	fmt.Println("Server stopped gracefully")
+	logger.Info("server stopped gracefully")
 	return nil
 }
+
+// printEndpoints prints the endpoint overview of the startup banner.
+func printEndpoints(port string) {
+	fmt.Printf("\nAPI server listening on http://localhost:%s\n", port)
+	fmt.Println("\nAvailable endpoints:")
+	fmt.Println("  GET  /health                     - Health check")
+	fmt.Println("  GET  /ready                      - Readiness (sync freshness)")
+	fmt.Println("  GET  /version                    - Version info")
+	fmt.Println("  GET  /metrics                    - Prometheus metrics")
+	fmt.Println("  GET  /api/v1/claims              - List claims")
+	fmt.Println("  GET  /api/v1/claims/{name}       - Get claim by name")
+	fmt.Println("  GET  /api/v1/claims/{name}/history - Claim change history")
+	fmt.Println("  GET  /api/v1/claims/{name}/manifest - Claim manifest from its repository")
+	fmt.Println("  GET  /api/v1/changes?since=      - Registry changesets")
+	fmt.Println("  GET  /api/v1/search?q=          - Search claims")
+	fmt.Println("  GET  /api/v1/stats               - Claim statistics")
+	fmt.Println("  GET  /api/v1/watch               - Watch claim changes (SSE)")
+	fmt.Println("  GET  /api/v1/sync/status         - Sync status")
+	fmt.Println("  POST /api/v1/sync                - Force sync (bearer SYNC_TOKEN)")
+	fmt.Println("  POST /api/v1/hooks/github        - GitHub push webhook")
+	fmt.Println("  GET  /openapi.yaml               - OpenAPI spec")
+	fmt.Println("  GET  /docs                       - API docs")
+}
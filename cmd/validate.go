@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+var validateOutput string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|->",
+	Short: "Validate a claim registry file",
+	Long: `Validate a claims/registry.yaml file against the same rules the server
+applies before serving a registry. Use "-" to read from stdin.
+
+Exits non-zero when the registry has errors; warnings are reported but do
+not fail validation.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runValidate,
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", "text", "Output format: text, json or sarif")
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	file := args[0]
+
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+		file = "<stdin>"
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+
+	_, violations, err := registry.Check(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	out := cmd.OutOrStdout()
+	switch validateOutput {
+	case "text":
+		writeValidateText(out, file, violations)
+	case "json":
+		err = writeValidateJSON(out, file, violations)
+	case "sarif":
+		err = writeValidateSARIF(out, file, violations)
+	default:
+		return fmt.Errorf("unsupported output format %q (expected text, json or sarif)", validateOutput)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n := violations.ErrorCount(); n > 0 {
+		return fmt.Errorf("%s: validation failed with %d error(s)", file, n)
+	}
+	return nil
+}
+
+// writeValidateText prints compiler-style findings followed by a summary.
+func writeValidateText(w io.Writer, file string, violations registry.Violations) {
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s:%d:%d: %s: %s: %s [%s]\n",
+			file, v.Line, v.Column, v.Severity, v.Path, v.Message, v.Rule)
+	}
+	errors := violations.ErrorCount()
+	fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n", file, errors, len(violations)-errors)
+}
+
+// writeValidateJSON prints the findings as a single JSON document.
+func writeValidateJSON(w io.Writer, file string, violations registry.Violations) error {
+	if violations == nil {
+		violations = registry.Violations{}
+	}
+	errors := violations.ErrorCount()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{
+		"file":       file,
+		"valid":      errors == 0,
+		"errors":     errors,
+		"warnings":   len(violations) - errors,
+		"violations": violations,
+	})
+}
+
+// SARIF 2.1.0 subset used for code scanning uploads.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// writeValidateSARIF prints the findings as a SARIF 2.1.0 log.
+func writeValidateSARIF(w io.Writer, file string, violations registry.Violations) error {
+	rules := []sarifRule{}
+	seen := map[string]bool{}
+	results := []sarifResult{}
+
+	for _, v := range violations {
+		if !seen[v.Rule] {
+			seen[v.Rule] = true
+			rules = append(rules, sarifRule{ID: v.Rule})
+		}
+
+		loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}}
+		if v.Line > 0 {
+			loc.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    v.Rule,
+			Level:     string(v.Severity),
+			Message:   sarifMessage{Text: v.Path + ": " + v.Message},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+
+	report := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "machinery-registry-api",
+				Version:        Version,
+				InformationURI: "https://github.com/stuttgart-things/machinery-registry-api",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validRegistryYAML = `apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    namespace: default
+    createdAt: "2026-02-05T10:58:33Z"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/hacky.yaml
+    status: active
+`
+
+const warningRegistryYAML = `apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    namespace: default
+    createdAt: "2026-02-05T10:58:33Z"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    status: archived
+`
+
+const errorRegistryYAML = `apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    category: cli
+    namespace: default
+    createdAt: yesterday
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/hacky.yaml
+    status: active
+`
+
+// runValidateCmd runs "validate -o output" on a file with the given contents.
+// It returns the file path, the printed output and the error that makes
+// Execute exit non-zero.
+func runValidateCmd(t *testing.T, contents, output string) (string, string, error) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "registry.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(contents), 0o644))
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"validate", "-o", output, file})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := rootCmd.Execute()
+	return file, out.String(), err
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+		text     []string // output lines; FILE stands for the registry path
+		errors   int
+		warnings int
+		rules    []string // rule of every finding, in order
+		levels   []string // SARIF level of every finding, in order
+	}{
+		{
+			name:     "valid",
+			contents: validRegistryYAML,
+			text:     []string{"FILE: 0 error(s), 0 warning(s)"},
+		},
+		{
+			name:     "warnings only",
+			contents: warningRegistryYAML,
+			text: []string{
+				`FILE:4:5: warning: claims[0].path: path is not set [recommended-field]`,
+				`FILE:12:13: warning: claims[0].status: status "archived" is not one of active, inactive, pending, deleted [unknown-status]`,
+				"FILE: 0 error(s), 2 warning(s)",
+			},
+			warnings: 2,
+			rules:    []string{"recommended-field", "unknown-status"},
+			levels:   []string{"warning", "warning"},
+		},
+		{
+			name:     "errors",
+			contents: errorRegistryYAML,
+			wantErr:  "validation failed with 2 error(s)",
+			text: []string{
+				`FILE:4:5: error: claims[0].template: template is required [required-field]`,
+				`FILE:7:16: error: claims[0].createdAt: createdAt "yesterday" is not an RFC3339 timestamp [invalid-timestamp]`,
+				"FILE: 2 error(s), 0 warning(s)",
+			},
+			errors: 2,
+			rules:  []string{"required-field", "invalid-timestamp"},
+			levels: []string{"error", "error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkExit := func(err error) {
+				t.Helper()
+				if tt.wantErr == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tt.wantErr)
+				}
+			}
+
+			file, out, err := runValidateCmd(t, tt.contents, "text")
+			checkExit(err)
+			want := strings.ReplaceAll(strings.Join(tt.text, "\n")+"\n", "FILE", file)
+			assert.Equal(t, want, out)
+
+			file, out, err = runValidateCmd(t, tt.contents, "json")
+			checkExit(err)
+			var report struct {
+				File       string
+				Valid      bool
+				Errors     int
+				Warnings   int
+				Violations []struct{ Rule string }
+			}
+			require.NoError(t, json.Unmarshal([]byte(out), &report), out)
+			assert.Equal(t, file, report.File)
+			assert.Equal(t, tt.errors == 0, report.Valid)
+			assert.Equal(t, tt.errors, report.Errors)
+			assert.Equal(t, tt.warnings, report.Warnings)
+			assert.NotNil(t, report.Violations, "violations is an array even when empty")
+			var rules []string
+			for _, v := range report.Violations {
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+
+			file, out, err = runValidateCmd(t, tt.contents, "sarif")
+			checkExit(err)
+			var log sarifLog
+			require.NoError(t, json.Unmarshal([]byte(out), &log), out)
+			assert.Equal(t, "2.1.0", log.Version)
+			require.Len(t, log.Runs, 1)
+			run := log.Runs[0]
+			assert.Equal(t, "machinery-registry-api", run.Tool.Driver.Name)
+			rules = nil
+			var levels []string
+			for _, r := range run.Results {
+				rules = append(rules, r.RuleID)
+				levels = append(levels, r.Level)
+				require.Len(t, r.Locations, 1)
+				assert.Equal(t, file, r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+				assert.NotNil(t, r.Locations[0].PhysicalLocation.Region)
+			}
+			assert.Equal(t, tt.rules, rules)
+			assert.Equal(t, tt.levels, levels)
+			assert.Len(t, run.Tool.Driver.Rules, len(tt.rules), "rules are listed once each")
+		})
+	}
+}
+
+func TestValidateUnsupportedOutput(t *testing.T) {
+	_, out, err := runValidateCmd(t, validRegistryYAML, "xml")
+	assert.ErrorContains(t, err, `unsupported output format "xml"`)
+	assert.Empty(t, out)
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// Output formats of the claim list and get endpoints, selected with the
+// format query parameter or the Accept header.
+const (
+	formatJSON   = "json"
+	formatYAML   = "yaml"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// formatMediaTypes maps media types to output formats. The first entry of a
+// format is the Content-Type it is served with; wildcards in Accept headers
+// match in this order.
+var formatMediaTypes = []struct{ format, mediaType string }{
+	{formatJSON, "application/json"},
+	{formatYAML, "application/yaml"},
+	{formatYAML, "application/x-yaml"},
+	{formatYAML, "text/yaml"},
+	{formatCSV, "text/csv"},
+	{formatNDJSON, "application/x-ndjson"},
+	{formatNDJSON, "application/ndjson"},
+}
+
+var errNotAcceptable = errors.New("not acceptable")
+
+// Headers carrying list metadata for formats without an envelope.
+const (
+	headerTotalItems      = "X-Total-Items"
+	headerContinue        = "X-Continue"
+	headerResourceVersion = "X-Resource-Version"
+)
+
+// negotiateFormat selects the output format from the format query parameter
+// or, without one, from the Accept header, defaulting to JSON. Endpoints
+// that serve only some formats pass them; the default is all of them. It
+// fails with errNotAcceptable if no supported format is acceptable.
+func negotiateFormat(w http.ResponseWriter, r *http.Request, formats ...string) (string, error) {
+	if len(formats) == 0 {
+		formats = []string{formatJSON, formatYAML, formatCSV, formatNDJSON}
+	}
+	w.Header().Add("Vary", "Accept")
+	if f := r.URL.Query().Get("format"); f != "" {
+		if slices.Contains(formats, f) {
+			return f, nil
+		}
+		return "", fmt.Errorf("%w (format %q)", notAcceptable(formats), f)
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return formatJSON, nil
+	}
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+	// Higher q first; ties go to the more specific range (RFC 9110, 12.5.1).
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		return cmp.Or(cmp.Compare(b.q, a.q), cmp.Compare(specificity(b.mediaType), specificity(a.mediaType)))
+	})
+
+	for _, mr := range ranges {
+		for _, f := range formatMediaTypes {
+			if slices.Contains(formats, f.format) && mediaTypeMatches(mr.mediaType, f.mediaType) {
+				return f.format, nil
+			}
+		}
+	}
+	return "", notAcceptable(formats)
+}
+
+// notAcceptable wraps errNotAcceptable with the media types of formats.
+func notAcceptable(formats []string) error {
+	types := make([]string, len(formats))
+	for i, f := range formats {
+		types[i] = contentType(f)
+	}
+	list := types[len(types)-1]
+	if len(types) > 1 {
+		list = strings.Join(types[:len(types)-1], ", ") + " and " + list
+	}
+	return fmt.Errorf("%w: supported media types are %s", errNotAcceptable, list)
+}
+
+// specificity ranks a media range: an exact type over "type/*" over "*/*".
+func specificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// mediaTypeMatches reports whether the media range of an Accept header,
+// such as "text/*", covers mediaType.
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// contentType returns the media type a format is served with.
+func contentType(format string) string {
+	for _, f := range formatMediaTypes {
+		if f.format == format {
+			return f.mediaType
+		}
+	}
+	return "application/json"
+}
+
+// outputFields returns the CSV columns for CSV output, from the columns
+// query parameter or the configured default, and otherwise the projection
+// requested by the fields query parameter.
+func (s *Server) outputFields(r *http.Request, format string) ([]string, *registry.Projection, error) {
+	if format != formatCSV {
+		projection, err := parseProjection(r)
+		return nil, projection, err
+	}
+	if r.URL.Query().Has("fields") {
+		return nil, nil, errors.New("fields is not supported with CSV output, use columns")
+	}
+	columns, err := registry.ParseFields(r.URL.Query().Get("columns"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if columns == nil {
+		columns = s.cfg.CSVColumns
+	}
+	return columns, nil, nil
+}
+
+// writeFormatted writes v as JSON or YAML.
+func writeFormatted(w http.ResponseWriter, format string, code int, v any) {
+	if format != formatYAML {
+		writeJSON(w, code, v)
+		return
+	}
+	data, err := toYAML(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType(formatYAML))
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// toYAML renders v as block-style YAML with the field names and order of
+// its JSON encoding.
+func toYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	// JSON is valid YAML; decoding into a node keeps the key order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	blockStyle(&node)
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	return []byte(b.String()), nil
+}
+
+// blockStyle clears the flow and quoting styles the JSON syntax left on n
+// and its children; the encoder still quotes strings that need it.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// setListHeaders sends list metadata as headers for formats that carry only
+// the items.
+func setListHeaders(w http.ResponseWriter, meta ListMeta) {
+	w.Header().Set(headerTotalItems, strconv.Itoa(meta.TotalItems))
+	if meta.ResourceVersion != "" {
+		w.Header().Set(headerResourceVersion, meta.ResourceVersion)
+	}
+	if meta.Continue != "" {
+		w.Header().Set(headerContinue, meta.Continue)
+	}
+}
+
+// writeCSV writes entries as CSV with a header row of the given columns.
+func writeCSV(w http.ResponseWriter, columns []string, entries []registry.ClaimEntry) {
+	w.Header().Set("Content-Type", contentType(formatCSV))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	row := make([]string, len(columns))
+	for i := range entries {
+		for j, c := range columns {
+			row[j], _ = entries[i].Field(c)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
+
+// writeList writes a list response: the items one per line for NDJSON,
+// otherwise resp as JSON or YAML.
+func writeList[T any](w http.ResponseWriter, format string, meta ListMeta, items []T, resp any) {
+	if format == formatNDJSON {
+		setListHeaders(w, meta)
+		writeNDJSON(w, items)
+		return
+	}
+	writeFormatted(w, format, http.StatusOK, resp)
+}
+
+// writeNDJSON streams items as newline-delimited JSON, one item per line.
+func writeNDJSON[T any](w http.ResponseWriter, items []T) {
+	w.Header().Set("Content-Type", contentType(formatNDJSON))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for i := range items {
+		if err := enc.Encode(items[i]); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		target, accept string
+		want           string
+		wantErr        bool
+	}{
+		{"/", "", formatJSON, false},
+		{"/", "application/json", formatJSON, false},
+		{"/", "application/yaml", formatYAML, false},
+		{"/", "text/yaml", formatYAML, false},
+		{"/", "text/csv", formatCSV, false},
+		{"/", "application/x-ndjson", formatNDJSON, false},
+		{"/", "text/html,application/xhtml+xml,*/*;q=0.8", formatJSON, false},
+		{"/", "text/*", formatYAML, false},
+		{"/", "application/json;q=0.5, text/csv", formatCSV, false},
+		{"/", "*/*, text/csv", formatCSV, false},
+		{"/", "text/*, application/x-ndjson", formatNDJSON, false},
+		{"/", "*/*;q=0.5, text/*;q=0.5, text/csv;q=0.5", formatCSV, false},
+		{"/", "application/xml", "", true},
+		{"/", "text/csv;q=0", "", true},
+		{"/?format=ndjson", "application/json", formatNDJSON, false},
+		{"/?format=xml", "", "", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		got, err := negotiateFormat(httptest.NewRecorder(), r)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, errNotAcceptable, tt.target+" "+tt.accept)
+			continue
+		}
+		require.NoError(t, err, tt.target+" "+tt.accept)
+		assert.Equal(t, tt.want, got, tt.target+" "+tt.accept)
+	}
+}
+
+func TestNegotiateFormatRestricted(t *testing.T) {
+	tests := []struct {
+		target, accept string
+		want           string
+	}{
+		{"/", "text/*", formatYAML},
+		{"/", "text/csv, application/json;q=0.5", formatJSON},
+		{"/", "text/csv", ""},
+		{"/?format=ndjson", "", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Header.Set("Accept", tt.accept)
+		got, err := negotiateFormat(httptest.NewRecorder(), r, formatJSON, formatYAML)
+		if tt.want == "" {
+			assert.ErrorIs(t, err, errNotAcceptable, tt.target+" "+tt.accept)
+			assert.ErrorContains(t, err, "supported media types are application/json and application/yaml")
+			continue
+		}
+		require.NoError(t, err, tt.target+" "+tt.accept)
+		assert.Equal(t, tt.want, got, tt.target+" "+tt.accept)
+	}
+}
+
+func TestListClaimsYAML(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?sort=name&limit=2", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Values("Vary"), "Accept")
+	assert.True(t, strings.HasPrefix(rr.Body.String(), "apiVersion: claim-registry.io/v1alpha1\nkind: ClaimList\n"), rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `createdAt: "2026-02-05T10:58:33Z"`, "timestamps stay strings")
+
+	var resp ClaimListResponse
+	var generic map[string]any
+	require.NoError(t, yaml.Unmarshal(rr.Body.Bytes(), &generic))
+	data, err := json.Marshal(generic)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &resp))
+	assert.Equal(t, 3, resp.Metadata.TotalItems)
+	assert.NotEmpty(t, resp.Metadata.Continue)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, "demo-project", resp.Items[0].Name)
+}
+
+func TestListClaimsCSV(t *testing.T) {
+	srv := setupTestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?format=csv&sort=name&limit=2", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "3", rr.Header().Get(headerTotalItems))
+	assert.NotEmpty(t, rr.Header().Get(headerContinue))
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, registry.FieldNames(), records[0])
+	assert.Equal(t, "demo-project", records[1][0])
+
+	// Custom columns
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?columns=name,status&category=cli&sort=name", nil)
+	req.Header.Set("Accept", "text/csv")
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "name,status\nhacky,active\nharvestervm-developer-martin,active\n", rr.Body.String())
+
+	// Configured default columns
+	srv = setupTestServerWithConfig(t, Config{CSVColumns: []string{"name", "template"}})
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?format=csv", nil))
+	assert.Equal(t, "name,template\nhacky,volumeclaim\n", rr.Body.String())
+
+	for _, target := range []string{
+		"/api/v1/claims?format=csv&columns=name,owner",
+		"/api/v1/claims?format=csv&expand=manifest",
+	} {
+		rr = httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
+
+func TestListClaimsNDJSON(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?sort=name", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "3", rr.Header().Get(headerTotalItems))
+	assert.Empty(t, rr.Header().Get(headerContinue))
+
+	var names []string
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var e registry.ClaimEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"demo-project", "hacky", "harvestervm-developer-martin"}, names)
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?format=ndjson", nil))
+	assert.Equal(t, 1, strings.Count(rr.Body.String(), "\n"))
+	assert.Contains(t, rr.Body.String(), `"name":"hacky"`)
+}
+
+func TestGetClaimFormats(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Body.String(), "name: hacky\ntemplate: volumeclaim\n"), rr.Body.String())
+
+	// Unsupported types are rejected before the lookup.
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/claims/nonexistent?format=xml", nil),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/claims", nil)
+			r.Header.Set("Accept", "application/xml")
+			return r
+		}(),
+	} {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code, req.URL.String())
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	}
+
+	// Errors stay JSON whatever was negotiated.
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/nonexistent?format=yaml", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"error":"claim not found"}`, rr.Body.String())
+}
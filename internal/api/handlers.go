@@ -2,77 +2,408 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ClaimListResponse wraps claims for the list endpoint
 type ClaimListResponse struct {
 	APIVersion string                `json:"apiVersion"`
 	Kind       string                `json:"kind"`
+	Metadata   ListMeta              `json:"metadata"`
 	Items      []registry.ClaimEntry `json:"items"`
 }
 
-// listClaims returns all claims, optionally filtered by query parameters.
-func (s *Server) listClaims(w http.ResponseWriter, r *http.Request) {
+// ListMeta carries pagination state for list responses
+type ListMeta struct {
+	Continue        string `json:"continue,omitempty"` // Token for the next page; empty on the last page
+	TotalItems      int    `json:"totalItems"`         // Number of matching claims across all pages
+	ResourceVersion string `json:"resourceVersion"`    // Watch from here to receive later changes
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
 
-	reg := s.syncer.GetRegistry()
-	if reg == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "registry not yet loaded",
-		})
+// writeError writes a JSON error body with the given status code.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+// startSpan starts a child span of the request's server span.
+func startSpan(r *http.Request, name string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := tracer().Start(r.Context(), name, trace.WithAttributes(attrs...))
+	return span
+}
+
+// listClaims returns claims, optionally filtered, sorted and paginated by
+// query parameters, as JSON, YAML, CSV or NDJSON. fields reduces the claims
+// to the given fields. With watch=true it streams changes instead; with
+// expand=manifest every claim carries its fetched manifest.
+func (s *Server) listClaims(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if watch, _ := strconv.ParseBool(query.Get("watch")); watch {
+		s.watchClaims(w, r)
 		return
 	}
 
-	category := r.URL.Query().Get("category")
-	template := r.URL.Query().Get("template")
-	status := r.URL.Query().Get("status")
-	source := r.URL.Query().Get("source")
+	format, err := negotiateFormat(w, r)
+	if err != nil {
+		writeError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+	columns, projection, err := s.outputFields(r, format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap, resourceVersion := s.registries.Current()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+		return
+	}
+
+	selector, err := listSelector(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	items := registry.FilterEntries(reg, category, template, status, source)
+	sortKeys, err := registry.ParseSort(query.Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	expand := query.Get("expand")
+	if expand != "" && expand != "manifest" {
+		writeError(w, http.StatusBadRequest, "expand must be manifest")
+		return
+	}
+	if expand != "" && format == formatCSV {
+		writeError(w, http.StatusBadRequest, "expand is not supported with CSV output")
+		return
+	}
+
+	cursor := listCursor{Version: snap.Version(), Query: queryHash(query)}
+	if token := query.Get("continue"); token != "" {
+		prev, err := decodeCursor(token)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if prev.Query != cursor.Query {
+			writeError(w, http.StatusBadRequest, errContinueQuery.Error())
+			return
+		}
+		if prev.Version != cursor.Version {
+			writeError(w, http.StatusGone, "continue token expired: the registry changed since the list was started, restart the list without continue")
+			return
+		}
+		cursor.Offset = prev.Offset
+	}
+
+	span := startSpan(r, "snapshot.select", attribute.String("selector", selector.String()))
+	items := snap.Select(selector)
+	span.SetAttributes(attribute.Int("claims.matched", len(items)))
+	span.End()
+
+	if len(sortKeys) > 0 {
+		span = startSpan(r, "claims.sort", attribute.Int("claims.count", len(items)))
+		registry.SortEntries(items, sortKeys)
+		span.End()
+	}
+
+	meta := ListMeta{TotalItems: len(items), ResourceVersion: resourceVersion}
+	items = items[min(cursor.Offset, len(items)):]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		cursor.Offset += limit
+		meta.Continue = cursor.encode()
+	}
 	if items == nil {
 		items = []registry.ClaimEntry{}
 	}
 
-	response := ClaimListResponse{
-		APIVersion: "claim-registry.io/v1alpha1",
-		Kind:       "ClaimList",
-		Items:      items,
+	if expand == "manifest" {
+		if len(items) > maxExpandItems {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("expand=manifest returns at most %d claims per page, set limit", maxExpandItems))
+			return
+		}
+		expanded := s.expandManifests(r, items)
+		if projection != nil {
+			views := projectExpanded(projection, expanded)
+			writeList(w, format, meta, views, ProjectedClaimListResponse{
+				APIVersion: "claim-registry.io/v1alpha1",
+				Kind:       "ClaimList",
+				Metadata:   meta,
+				Items:      views,
+			})
+			return
+		}
+		writeList(w, format, meta, expanded, ExpandedClaimListResponse{
+			APIVersion: "claim-registry.io/v1alpha1",
+			Kind:       "ClaimList",
+			Metadata:   meta,
+			Items:      expanded,
+		})
+		return
+	}
+
+	switch {
+	case format == formatCSV:
+		setListHeaders(w, meta)
+		writeCSV(w, columns, items)
+	case projection != nil:
+		views := projectClaims(projection, items)
+		writeList(w, format, meta, views, ProjectedClaimListResponse{
+			APIVersion: "claim-registry.io/v1alpha1",
+			Kind:       "ClaimList",
+			Metadata:   meta,
+			Items:      views,
+		})
+	default:
+		writeList(w, format, meta, items, ClaimListResponse{
+			APIVersion: "claim-registry.io/v1alpha1",
+			Kind:       "ClaimList",
+			Metadata:   meta,
+			Items:      items,
+		})
 	}
+}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+// exactMatchParams are the query parameters that filter on a single field
+// value; they are shorthand for the equivalent fieldSelector terms.
+var exactMatchParams = []string{"category", "template", "status", "source", "registry"}
+
+// listSelector combines the fieldSelector query parameter with the
+// exact-match shorthand parameters, including param.<key> for claim
+// parameters, into a single selector.
+func listSelector(query url.Values) (registry.Selector, error) {
+	selector, err := registry.ParseSelector(query.Get("fieldSelector"))
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range exactMatchParams {
+		if v := query.Get(field); v != "" {
+			selector = append(selector, registry.Requirement{
+				Field:    field,
+				Operator: registry.OpEquals,
+				Values:   []string{v},
+			})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		if !strings.HasPrefix(key, registry.ParamPrefix) {
+			continue
+		}
+		if key == registry.ParamPrefix {
+			return nil, fmt.Errorf("invalid parameter filter %q: missing key", key)
+		}
+		if v := query.Get(key); v != "" {
+			selector = append(selector, registry.Requirement{
+				Field:    key,
+				Operator: registry.OpEquals,
+				Values:   []string{v},
+			})
+		}
+	}
+	return selector, nil
 }
 
-// getClaim returns a single claim by name.
+// getClaim returns a single claim by name as JSON, YAML, CSV or NDJSON,
+// optionally reduced to the fields query parameter.
 func (s *Server) getClaim(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	format, err := negotiateFormat(w, r)
+	if err != nil {
+		writeError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+	columns, projection, err := s.outputFields(r, format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	reg := s.syncer.GetRegistry()
-	if reg == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "registry not yet loaded",
-		})
+	snap := s.registries.Snapshot()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
 		return
 	}
 
 	vars := mux.Vars(r)
 	name := vars["name"]
 
-	entry := registry.FindEntry(reg, name)
-	if entry == nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "claim not found",
+	span := startSpan(r, "snapshot.get", attribute.String("claim.name", name))
+	entry, ok := snap.Get(name)
+	span.SetAttributes(attribute.Bool("claim.found", ok))
+	span.End()
+	if !ok {
+		writeError(w, http.StatusNotFound, "claim not found")
+		return
+	}
+
+	switch format {
+	case formatCSV:
+		writeCSV(w, columns, []registry.ClaimEntry{entry})
+	case formatNDJSON:
+		writeNDJSON(w, []any{projectClaim(projection, &entry)})
+	default:
+		writeFormatted(w, format, http.StatusOK, projectClaim(projection, &entry))
+	}
+}
+
+// SearchResponse wraps ranked hits for the search endpoint
+type SearchResponse struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Query      string         `json:"query"`
+	Items      []registry.Hit `json:"items"`
+}
+
+// defaultSearchLimit caps search results when no limit is given.
+const defaultSearchLimit = 20
+
+// searchClaims returns claims ranked by relevance to the q query parameter,
+// optionally reduced to the fields query parameter.
+func (s *Server) searchClaims(w http.ResponseWriter, r *http.Request) {
+	snap := s.registries.Snapshot()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	projection, err := parseProjection(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span := startSpan(r, "snapshot.search", attribute.String("search.query", q), attribute.Int("search.limit", limit))
+	hits := snap.Search(q, limit)
+	span.SetAttributes(attribute.Int("search.hits", len(hits)))
+	span.End()
+	if hits == nil {
+		hits = []registry.Hit{}
+	}
+
+	if projection != nil {
+		writeJSON(w, http.StatusOK, ProjectedSearchResponse{
+			APIVersion: "claim-registry.io/v1alpha1",
+			Kind:       "SearchResult",
+			Query:      q,
+			Items:      projectHits(projection, hits),
 		})
 		return
 	}
+	writeJSON(w, http.StatusOK, SearchResponse{
+		APIVersion: "claim-registry.io/v1alpha1",
+		Kind:       "SearchResult",
+		Query:      q,
+		Items:      hits,
+	})
+}
+
+// StatsResponse carries aggregate counts for the stats endpoint
+type StatsResponse struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Total      int                `json:"total"`
+	GroupBy    []string           `json:"groupBy,omitempty"`
+	Groups     []registry.Group   `json:"groups,omitempty"`
+	Histogram  *HistogramResponse `json:"histogram,omitempty"`
+}
+
+// HistogramResponse is a creation-time histogram
+type HistogramResponse struct {
+	Interval registry.Interval `json:"interval"`
+	Buckets  []registry.Bucket `json:"buckets"`
+	Undated  int               `json:"undated"` // Claims without a valid createdAt
+}
+
+// claimStats returns claim counts grouped by fields and bucketed by creation
+// time, over the claims matching the listClaims filters.
+func (s *Server) claimStats(w http.ResponseWriter, r *http.Request) {
+	snap := s.registries.Snapshot()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+		return
+	}
+
+	query := r.URL.Query()
+	selector, err := listSelector(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	groupBy, err := registry.ParseGroupBy(query.Get("groupBy"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	span := startSpan(r, "snapshot.select", attribute.String("selector", selector.String()))
+	items := snap.Select(selector)
+	span.SetAttributes(attribute.Int("claims.matched", len(items)))
+	span.End()
+
+	resp := StatsResponse{
+		APIVersion: "claim-registry.io/v1alpha1",
+		Kind:       "ClaimStats",
+		Total:      len(items),
+		GroupBy:    groupBy,
+		Groups:     registry.GroupCounts(items, groupBy),
+	}
+
+	if v := query.Get("interval"); v != "" {
+		interval, err := registry.ParseInterval(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		buckets, undated, err := registry.Histogram(items, interval)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp.Histogram = &HistogramResponse{Interval: interval, Buckets: buckets, Undated: undated}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(entry)
+// syncStatus returns the state of the background sync of all registries.
+func (s *Server) syncStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.registries.Status())
 }
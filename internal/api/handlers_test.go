@@ -1,14 +1,23 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
 	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
@@ -53,6 +62,12 @@ claims:
 // setupTestServer creates a Server backed by an httptest mock serving testRegistryYAML.
 func setupTestServer(t *testing.T) *Server {
 	t.Helper()
+	return setupTestServerWithConfig(t, Config{})
+}
+
+// setupTestServerWithConfig is setupTestServer with custom server settings.
+func setupTestServerWithConfig(t *testing.T, cfg Config) *Server {
+	t.Helper()
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(testRegistryYAML))
@@ -63,10 +78,11 @@ func setupTestServer(t *testing.T) *Server {
 		Repo:    "test/repo",
 		BaseURL: ts.URL,
 	})
-	err := syncer.InitialSync(context.Background())
+	registries := isync.NewFederation(isync.FederationConfig{}, syncer)
+	err := registries.InitialSync(context.Background())
 	require.NoError(t, err)
 
-	return NewServer(syncer)
+	return NewServer(registries, cfg)
 }
 
 func TestHealthEndpoint(t *testing.T) {
@@ -204,3 +220,459 @@ func TestGetClaimNotFound(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "claim not found", body["error"])
 }
+
+func TestSyncStatusEndpoint(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sync/status", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var st isync.FederationStatus
+	err := json.Unmarshal(rr.Body.Bytes(), &st)
+	require.NoError(t, err)
+	assert.Equal(t, 3, st.ClaimCount)
+	require.Len(t, st.Registries, 1)
+	assert.Equal(t, "test/repo", st.Registries[0].Name)
+	assert.Equal(t, 3, st.Registries[0].ClaimCount)
+	assert.Equal(t, 0, st.Registries[0].ConsecutiveFailures)
+	assert.False(t, st.Registries[0].LastSuccess.IsZero())
+	assert.False(t, st.Registries[0].Stale)
+}
+
+func TestReadinessEndpoint(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"ready"`)
+}
+
+func TestReadinessEndpointNotLoaded(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(ts.Close)
+
+	syncer := isync.NewSyncer(isync.Config{Repo: "test/repo", BaseURL: ts.URL})
+	registries := isync.NewFederation(isync.FederationConfig{}, syncer)
+	require.Error(t, registries.InitialSync(context.Background()))
+	srv := NewServer(registries, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"degraded"`)
+	assert.Contains(t, rr.Body.String(), "unexpected status 500")
+}
+
+func TestReadinessEndpointDegradedRegistry(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testRegistryYAML))
+	}))
+	t.Cleanup(ok.Close)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(broken.Close)
+
+	registries := isync.NewFederation(isync.FederationConfig{},
+		isync.NewSyncer(isync.Config{Name: "harvester", Repo: "test/harvester", BaseURL: ok.URL}),
+		isync.NewSyncer(isync.Config{Name: "vsphere", Repo: "test/vsphere", BaseURL: broken.URL}),
+	)
+	require.NoError(t, registries.InitialSync(context.Background()))
+	srv := NewServer(registries, Config{})
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"ready"`)
+	assert.Contains(t, rr.Body.String(), `"degraded":["vsphere"]`)
+}
+
+func TestListClaimsWithRegistryFilter(t *testing.T) {
+	newSyncer := func(name, body string) *isync.Syncer {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+		return isync.NewSyncer(isync.Config{Name: name, Repo: "test/" + name, BaseURL: ts.URL})
+	}
+
+	registries := isync.NewFederation(isync.FederationConfig{},
+		newSyncer("harvester", testRegistryYAML),
+		newSyncer("vsphere", `
+apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: vsphere-vm
+    template: vspherevm
+    category: infra
+    status: active
+`),
+	)
+	require.NoError(t, registries.InitialSync(context.Background()))
+	srv := NewServer(registries, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Len(t, resp.Items, 4)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/claims?registry=vsphere", nil)
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "vsphere-vm", resp.Items[0].Name)
+	assert.Equal(t, "vsphere", resp.Items[0].Registry)
+}
+
+func TestListClaimsSorted(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?sort=category,-name", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 3)
+	assert.Equal(t, "harvestervm-developer-martin", resp.Items[0].Name)
+	assert.Equal(t, "hacky", resp.Items[1].Name)
+	assert.Equal(t, "demo-project", resp.Items[2].Name)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/claims?sort=owner", nil)
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "unknown sort field")
+}
+
+func TestListClaimsPagination(t *testing.T) {
+	srv := setupTestServer(t)
+
+	var names []string
+	url := "/api/v1/claims?sort=name&limit=2"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp ClaimListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, 3, resp.Metadata.TotalItems)
+		assert.LessOrEqual(t, len(resp.Items), 2)
+		for _, e := range resp.Items {
+			names = append(names, e.Name)
+		}
+
+		if resp.Metadata.Continue == "" {
+			break
+		}
+		url = "/api/v1/claims?sort=name&limit=2&continue=" + resp.Metadata.Continue
+	}
+
+	assert.Equal(t, []string{"demo-project", "hacky", "harvestervm-developer-martin"}, names)
+}
+
+func TestListClaimsPaginationInvalid(t *testing.T) {
+	srv := setupTestServer(t)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/claims?limit=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/claims?limit=abc").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/claims?continue=not-a-token").Code)
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(get("/api/v1/claims?limit=1").Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Metadata.Continue)
+
+	rr := get("/api/v1/claims?limit=1&category=cli&continue=" + resp.Metadata.Continue)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "different query")
+}
+
+func TestListClaimsContinueAfterSnapshotSwap(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+
+	syncer := isync.NewSyncer(isync.Config{Repo: "test/repo", BaseURL: ts.URL})
+	registries := isync.NewFederation(isync.FederationConfig{}, syncer)
+	require.NoError(t, registries.InitialSync(context.Background()))
+	srv := NewServer(registries, Config{})
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(get("/api/v1/claims?limit=1").Body.Bytes(), &resp))
+	token := resp.Metadata.Continue
+	require.NotEmpty(t, token)
+
+	// An unchanged re-sync keeps the token valid
+	require.NoError(t, registries.InitialSync(context.Background()))
+	assert.Equal(t, http.StatusOK, get("/api/v1/claims?limit=1&continue="+token).Code)
+
+	body = strings.Replace(testRegistryYAML, "status: inactive", "status: active", 1)
+	require.NoError(t, registries.InitialSync(context.Background()))
+
+	rr := get("/api/v1/claims?limit=1&continue=" + token)
+	assert.Equal(t, http.StatusGone, rr.Code)
+	assert.Contains(t, rr.Body.String(), "continue token expired")
+}
+
+func TestListClaimsWithFieldSelector(t *testing.T) {
+	srv := setupTestServer(t)
+
+	get := func(selector string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?fieldSelector="+url.QueryEscape(selector), nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("namespace!=default,template in (harborproject,volumeclaim)")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "demo-project", resp.Items[0].Name)
+
+	rr = get("template in (harborproject")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Contains(t, body["error"], "at position 27: expected ',' or ')'")
+}
+
+func TestListClaimsFieldSelectorCombinesWithFilters(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims?category=cli&fieldSelector=name!%3Dhacky", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "harvestervm-developer-martin", resp.Items[0].Name)
+}
+
+func TestSearchClaims(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=martin", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp SearchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "SearchResult", resp.Kind)
+	assert.Equal(t, "martin", resp.Query)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "harvestervm-developer-martin", resp.Items[0].Claim.Name)
+	assert.Equal(t, "harvestervm-developer-<em>martin</em>", resp.Items[0].Highlights["name"])
+	assert.Positive(t, resp.Items[0].Score)
+}
+
+func TestSearchClaimsInvalid(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, url := range []string{"/api/v1/search", "/api/v1/search?q=+", "/api/v1/search?q=martin&limit=-1"} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestClaimStats(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats?groupBy=category,status&interval=day", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp StatsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "ClaimStats", resp.Kind)
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, []string{"category", "status"}, resp.GroupBy)
+	require.Len(t, resp.Groups, 2)
+	assert.Equal(t, map[string]string{"category": "cli", "status": "active"}, resp.Groups[0].Keys)
+	assert.Equal(t, 2, resp.Groups[0].Count)
+
+	require.NotNil(t, resp.Histogram)
+	assert.Equal(t, []registry.Bucket{
+		{Start: "2026-02-05", Count: 2},
+		{Start: "2026-02-06", Count: 0},
+		{Start: "2026-02-07", Count: 1},
+	}, resp.Histogram.Buckets)
+}
+
+func TestClaimStatsHonorsFilters(t *testing.T) {
+	srv := setupTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats?groupBy=template&fieldSelector=namespace%3Ddefault", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp StatsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Total)
+	assert.Len(t, resp.Groups, 2)
+	assert.Nil(t, resp.Histogram)
+}
+
+func TestClaimStatsInvalid(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, url := range []string{
+		"/api/v1/stats?groupBy=path",
+		"/api/v1/stats?interval=year",
+		"/api/v1/stats?fieldSelector=owner%3Dme",
+	} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	srv := setupTestServer(t)
+
+	for _, path := range []string{"/api/v1/claims/hacky", "/api/v1/claims/demo-project", "/api/v1/claims/nonexistent"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		srv.router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `machinery_registry_http_requests_total{code="200",method="GET",route="/api/v1/claims/{name}"}`)
+	assert.Contains(t, body, `machinery_registry_http_requests_total{code="404",method="GET",route="/api/v1/claims/{name}"}`)
+	assert.NotContains(t, body, `route="/api/v1/claims/hacky"`)
+	assert.Contains(t, body, "machinery_registry_http_requests_in_flight")
+	assert.Contains(t, body, `machinery_registry_sync_attempts_total{registry="test/repo",result="changed"}`)
+	assert.Contains(t, body, `machinery_registry_claims{status="active",template="volumeclaim"} 1`)
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	srv := setupTestServer(t)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req-123")
+	srv.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	server, ok := spans["GET /api/v1/claims/{name}"]
+	require.True(t, ok, "server span named after the route template")
+	assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), attribute.String("http.request.id", "req-123"))
+	assert.Contains(t, server.Attributes(), attribute.String("http.route", "/api/v1/claims/{name}"))
+
+	get, ok := spans["snapshot.get"]
+	require.True(t, ok)
+	assert.Equal(t, server.SpanContext().SpanID(), get.Parent().SpanID())
+	assert.Contains(t, get.Attributes(), attribute.Bool("claim.found", true))
+
+	before := len(recorder.Ended())
+	srv.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Len(t, recorder.Ended(), before, "probes are not traced")
+}
+
+func TestRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	srv := setupTestServerWithConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky", nil)
+	req.Header.Set("X-Request-ID", "req-log")
+	srv.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "request", entry["msg"])
+	assert.Equal(t, "req-log", entry["requestId"])
+	assert.Equal(t, "/api/v1/claims/{name}", entry["route"])
+	assert.Equal(t, "/api/v1/claims/hacky", entry["path"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+}
+
+func TestPanicLogging(t *testing.T) {
+	var buf bytes.Buffer
+	srv := setupTestServerWithConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+	srv.router.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) { panic("boom") })
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("X-Request-ID", "req-panic")
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Equal(t, "req-panic", entry["requestId"])
+}
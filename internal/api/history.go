@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// ChangesetListResponse wraps changesets for the changes endpoint
+type ChangesetListResponse struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Items      []sync.Changeset `json:"items"`
+}
+
+// ClaimHistoryResponse wraps the changes to one claim
+type ClaimHistoryResponse struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Name       string             `json:"name"`
+	Items      []sync.ClaimChange `json:"items"`
+}
+
+// listChanges returns the retained changesets, oldest first. since is an
+// RFC3339 timestamp or a registry revision; registry restricts the result
+// to one registry.
+func (s *Server) listChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	history := s.registries.History()
+
+	since := query.Get("since")
+	var sets []sync.Changeset
+	if t, err := time.Parse(time.RFC3339, since); since == "" || err == nil {
+		sets = history.Since(t)
+	} else {
+		var ok bool
+		if sets, ok = history.After(since); !ok && !s.isCurrentRevision(since) {
+			writeError(w, http.StatusGone, fmt.Sprintf("revision %q is not in the retained change history", since))
+			return
+		}
+	}
+
+	items := []sync.Changeset{}
+	for _, cs := range sets {
+		if reg := query.Get("registry"); reg == "" || cs.Registry == reg {
+			items = append(items, cs)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ChangesetListResponse{
+		APIVersion: "claim-registry.io/v1alpha1",
+		Kind:       "ChangesetList",
+		Items:      items,
+	})
+}
+
+// isCurrentRevision reports whether a registry currently serves revision,
+// e.g. the revision of its initial load, which has no changeset.
+func (s *Server) isCurrentRevision(revision string) bool {
+	for _, syncer := range s.registries.Syncers() {
+		if syncer.Revision() == revision {
+			return true
+		}
+	}
+	return false
+}
+
+// claimHistory returns the retained changes to a claim, newest first.
+func (s *Server) claimHistory(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	items := s.registries.History().Claim(name)
+	if len(items) == 0 {
+		// Without recorded changes, only claims that exist have a history.
+		snap := s.registries.Snapshot()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+			return
+		}
+		if _, ok := snap.Get(name); !ok {
+			writeError(w, http.StatusNotFound, "claim not found")
+			return
+		}
+		items = []sync.ClaimChange{}
+	}
+
+	writeJSON(w, http.StatusOK, ClaimHistoryResponse{
+		APIVersion: "claim-registry.io/v1alpha1",
+		Kind:       "ClaimHistory",
+		Name:       name,
+		Items:      items,
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestChangesAndHistory(t *testing.T) {
+	ts, update := setupLiveServer(t)
+
+	var status isync.FederationStatus
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/sync/status", &status))
+	initial := status.Registries[0].Revision
+
+	var changes ChangesetListResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes?since="+initial, &changes))
+	assert.Empty(t, changes.Items, "no changes since the initial load")
+
+	start := time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+	update(updatedRegistryYAML)
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes", &changes))
+	require.Len(t, changes.Items, 1)
+	cs := changes.Items[0]
+	assert.Equal(t, "ChangesetList", changes.Kind)
+	assert.Equal(t, initial, cs.PreviousRevision)
+	assert.Len(t, cs.Changes, 3)
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes?since="+initial, &changes))
+	assert.Len(t, changes.Items, 1)
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes?since="+cs.Revision, &changes))
+	assert.Empty(t, changes.Items)
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes?since="+start, &changes))
+	assert.Len(t, changes.Items, 1)
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/changes?registry=other", &changes))
+	assert.Empty(t, changes.Items)
+	assert.Equal(t, http.StatusGone, getJSON(t, ts.URL+"/api/v1/changes?since=deadbeef", &changes))
+
+	var history ClaimHistoryResponse
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/claims/hacky/history", &history))
+	require.Len(t, history.Items, 1)
+	assert.Equal(t, registry.Modified, history.Items[0].Type)
+	assert.Contains(t, history.Items[0].Fields, registry.FieldChange{Field: "status", Old: "active", New: "inactive"})
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/claims/demo-project/history", &history))
+	require.Len(t, history.Items, 1)
+	assert.Equal(t, registry.Deleted, history.Items[0].Type, "deleted claims keep their history")
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/v1/claims/harvestervm-developer-martin/history", &history))
+	assert.Empty(t, history.Items)
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts.URL+"/api/v1/claims/nonexistent/history", &history))
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// maxWebhookBody limits the size of accepted webhook payloads.
+const maxWebhookBody = 5 << 20
+
+// pushEvent is the subset of a GitHub push event payload we inspect.
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// touches reports whether any commit in the push added, modified or removed path.
+func (e *pushEvent) touches(path string) bool {
+	for _, c := range e.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, f := range files {
+				if f == path {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// mismatch returns why the push is irrelevant to a registry, or "" if it
+// touched the registry file on the tracked branch.
+func (e *pushEvent) mismatch(cfg sync.Config) string {
+	switch {
+	case e.Ref != "refs/heads/"+cfg.Branch:
+		return "branch not tracked"
+	case isRepoSlug(cfg.Repo) && !strings.EqualFold(e.Repository.FullName, cfg.Repo):
+		return "repository not tracked"
+	case !e.touches(cfg.Path):
+		return "registry file not changed"
+	}
+	return ""
+}
+
+// isRepoSlug reports whether repo is an "owner/name" GitHub slug rather than
+// a remote URL, i.e. whether it can be compared to repository.full_name.
+func isRepoSlug(repo string) bool {
+	return strings.Count(repo, "/") == 1 && !strings.Contains(repo, ":")
+}
+
+// validSignature checks a GitHub X-Hub-Signature-256 header against body.
+func validSignature(secret, header string, body []byte) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// githubWebhook triggers an immediate sync of every registry whose file was
+// touched by a GitHub push event on its tracked branch.
+func (s *Server) githubWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.WebhookSecret == "" {
+		writeError(w, http.StatusForbidden, "webhook secret not configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "reading request body")
+		return
+	}
+
+	if !validSignature(s.cfg.WebhookSecret, r.Header.Get("X-Hub-Signature-256"), body) {
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	switch r.Header.Get("X-GitHub-Event") {
+	case "ping":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	case "push":
+	default:
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "unsupported event"})
+		return
+	}
+
+	var event pushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid push payload")
+		return
+	}
+
+	// Trigger every registry the push is relevant to; otherwise report why
+	// the push was ignored.
+	var (
+		matched []*sync.Syncer
+		reason  string
+	)
+	for _, syncer := range s.registries.Syncers() {
+		if why := event.mismatch(syncer.Config()); why != "" {
+			if reason == "" {
+				reason = why
+			}
+			continue
+		}
+		matched = append(matched, syncer)
+	}
+	if len(matched) == 0 {
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": reason})
+		return
+	}
+
+	queued := false
+	for _, syncer := range matched {
+		if syncer.Trigger() {
+			queued = true
+		}
+	}
+	s.respondTriggered(w, r, "webhook", queued)
+}
+
+// forceSync triggers an immediate sync for callers presenting the sync token.
+func (s *Server) forceSync(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.SyncToken == "" {
+		writeError(w, http.StatusForbidden, "sync token not configured")
+		return
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.SyncToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return
+	}
+
+	s.respondTriggered(w, r, "api", s.registries.Trigger())
+}
+
+// respondTriggered reports whether a sync was queued or coalesced into a
+// pending one.
+func (s *Server) respondTriggered(w http.ResponseWriter, r *http.Request, origin string, queued bool) {
+	status := "sync triggered"
+	if !queued {
+		status = "sync already pending"
+	}
+	attrs := append(requestAttrs(r), slog.String("origin", origin), slog.String("status", status))
+	s.log.LogAttrs(r.Context(), slog.LevelInfo, "sync requested", attrs...)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": status})
+}
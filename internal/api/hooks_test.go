@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWebhookSecret = "s3cret"
+
+func signPayload(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func pushPayload(ref, repo, file string) string {
+	return `{"ref":"` + ref + `","repository":{"full_name":"` + repo + `"},` +
+		`"commits":[{"added":[],"modified":["` + file + `"],"removed":[]}]}`
+}
+
+func postWebhook(srv *Server, event, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/hooks/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGitHubWebhookTriggersSync(t *testing.T) {
+	srv := setupTestServerWithConfig(t, Config{WebhookSecret: testWebhookSecret})
+
+	body := pushPayload("refs/heads/main", "test/repo", "claims/registry.yaml")
+	rr := postWebhook(srv, "push", body, signPayload(testWebhookSecret, body))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), "sync triggered")
+
+	// The loop is not running, so the second push coalesces into the first
+	rr = postWebhook(srv, "push", body, signPayload(testWebhookSecret, body))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), "sync already pending")
+}
+
+func TestGitHubWebhookInvalidSignature(t *testing.T) {
+	srv := setupTestServerWithConfig(t, Config{WebhookSecret: testWebhookSecret})
+
+	body := pushPayload("refs/heads/main", "test/repo", "claims/registry.yaml")
+	rr := postWebhook(srv, "push", body, signPayload("wrong", body))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = postWebhook(srv, "push", body, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestGitHubWebhookNotConfigured(t *testing.T) {
+	srv := setupTestServer(t)
+
+	body := pushPayload("refs/heads/main", "test/repo", "claims/registry.yaml")
+	rr := postWebhook(srv, "push", body, signPayload(testWebhookSecret, body))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestGitHubWebhookIgnoredPushes(t *testing.T) {
+	srv := setupTestServerWithConfig(t, Config{WebhookSecret: testWebhookSecret})
+
+	tests := []struct {
+		name, body, reason string
+	}{
+		{"other branch", pushPayload("refs/heads/feature", "test/repo", "claims/registry.yaml"), "branch not tracked"},
+		{"other repo", pushPayload("refs/heads/main", "other/repo", "claims/registry.yaml"), "repository not tracked"},
+		{"other file", pushPayload("refs/heads/main", "test/repo", "README.md"), "registry file not changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postWebhook(srv, "push", tt.body, signPayload(testWebhookSecret, tt.body))
+			assert.Equal(t, http.StatusAccepted, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.reason)
+		})
+	}
+
+	rr := postWebhook(srv, "ping", `{}`, signPayload(testWebhookSecret, `{}`))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "pong")
+}
+
+func TestForceSync(t *testing.T) {
+	srv := setupTestServerWithConfig(t, Config{SyncToken: "tok"})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	req.Header.Set("Authorization", "Bearer tok")
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), "sync triggered")
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	gosync "sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxExpandItems caps the claims whose manifests one list response expands.
+const maxExpandItems = 100
+
+// expandConcurrency bounds the manifest fetches of one expanded list.
+const expandConcurrency = 8
+
+// ClaimManifestResponse wraps the parsed manifest of a claim
+type ClaimManifestResponse struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	Repository string    `json:"repository"`
+	Path       string    `json:"path"`
+	Revision   string    `json:"revision,omitempty"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+	Manifest   any       `json:"manifest"`
+}
+
+// ExpandedClaim is a claim with its manifest, as listed with expand=manifest
+type ExpandedClaim struct {
+	registry.ClaimEntry
+	Manifest      any    `json:"manifest,omitempty"`
+	ManifestError string `json:"manifestError,omitempty"`
+}
+
+// ExpandedClaimListResponse wraps expanded claims for the list endpoint
+type ExpandedClaimListResponse struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   ListMeta        `json:"metadata"`
+	Items      []ExpandedClaim `json:"items"`
+}
+
+// errNoManifestReference is returned for claims without repository or path.
+var errNoManifestReference = errors.New("claim has no manifest reference (repository and path)")
+
+// manifestError maps a fetch error to a status code.
+func manifestError(err error) int {
+	switch {
+	case errors.Is(err, errNoManifestReference), errors.Is(err, manifest.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, manifest.ErrInvalidReference):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// fetchManifest fetches the manifest referenced by e.
+func (s *Server) fetchManifest(ctx context.Context, e *registry.ClaimEntry) (*manifest.Manifest, error) {
+	if e.Repository == "" || e.Path == "" {
+		return nil, errNoManifestReference
+	}
+	return s.cfg.Manifests.Get(ctx, e.Repository, e.Path)
+}
+
+// claimManifest returns the claim manifest referenced by the entry's
+// repository and path, parsed to JSON or, when YAML is negotiated with the
+// format query parameter or the Accept header, as the raw file.
+func (s *Server) claimManifest(w http.ResponseWriter, r *http.Request) {
+	snap := s.registries.Snapshot()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	entry, ok := snap.Get(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "claim not found")
+		return
+	}
+
+	format, err := negotiateFormat(w, r, formatJSON, formatYAML)
+	if err != nil {
+		writeError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
+	span := startSpan(r, "manifest.get",
+		attribute.String("manifest.repository", entry.Repository),
+		attribute.String("manifest.path", entry.Path))
+	m, err := s.fetchManifest(r.Context(), &entry)
+	span.End()
+	if err != nil {
+		writeError(w, manifestError(err), err.Error())
+		return
+	}
+
+	if format == formatYAML {
+		w.Header().Set("Content-Type", contentType(formatYAML))
+		w.WriteHeader(http.StatusOK)
+		w.Write(m.Data)
+		return
+	}
+
+	parsed, err := m.Parse()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ClaimManifestResponse{
+		APIVersion: "claim-registry.io/v1alpha1",
+		Kind:       "ClaimManifest",
+		Name:       entry.Name,
+		Repository: m.Repository,
+		Path:       m.Path,
+		Revision:   m.Revision,
+		FetchedAt:  m.FetchedAt,
+		Manifest:   parsed,
+	})
+}
+
+// expandManifests fetches the manifests of items concurrently. Failures
+// are reported per claim rather than failing the list.
+func (s *Server) expandManifests(r *http.Request, items []registry.ClaimEntry) []ExpandedClaim {
+	span := startSpan(r, "manifest.expand", attribute.Int("claims.count", len(items)))
+	defer span.End()
+
+	expanded := make([]ExpandedClaim, len(items))
+	sem := make(chan struct{}, expandConcurrency)
+	var wg gosync.WaitGroup
+	for i := range items {
+		expanded[i].ClaimEntry = items[i]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e *ExpandedClaim) {
+			defer func() { <-sem; wg.Done() }()
+
+			m, err := s.fetchManifest(r.Context(), &e.ClaimEntry)
+			if err == nil {
+				e.Manifest, err = m.Parse()
+			}
+			if err != nil {
+				e.ManifestError = err.Error()
+			}
+		}(&expanded[i])
+	}
+	wg.Wait()
+	return expanded
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+const hackyManifestYAML = `apiVersion: resources.stuttgart-things.com/v1alpha1
+kind: VolumeClaim
+metadata:
+  name: hacky
+spec:
+  size: 10Gi
+  parameters:
+    cpu: 4
+    disk:
+      size: 20Gi
+`
+
+// newManifestUpstream serves the hacky manifest from a fake GitHub; the
+// other claims' manifests are missing.
+func newManifestUpstream(t *testing.T) *manifest.Fetcher {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stuttgart-things/harvester/HEAD/claims/cli/hacky.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte(hackyManifestYAML))
+	}))
+	t.Cleanup(upstream.Close)
+	return manifest.NewFetcher(manifest.Config{BaseURL: upstream.URL})
+}
+
+func setupManifestServer(t *testing.T) *Server {
+	t.Helper()
+	return setupTestServerWithConfig(t, Config{Manifests: newManifestUpstream(t)})
+}
+
+func TestClaimManifest(t *testing.T) {
+	srv := setupManifestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky/manifest", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp ClaimManifestResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "ClaimManifest", resp.Kind)
+	assert.Equal(t, "hacky", resp.Name)
+	assert.Equal(t, "stuttgart-things/harvester", resp.Repository)
+	assert.Equal(t, "claims/cli/hacky.yaml", resp.Path)
+	assert.Equal(t, `"abc"`, resp.Revision)
+	assert.Equal(t, "VolumeClaim", resp.Manifest.(map[string]any)["kind"])
+
+	// Raw YAML by query parameter or Accept header.
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky/manifest?format=yaml", nil),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky/manifest", nil)
+			r.Header.Set("Accept", "application/yaml")
+			return r
+		}(),
+	} {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/yaml", rr.Header().Get("Content-Type"))
+		assert.Equal(t, hackyManifestYAML, rr.Body.String())
+	}
+}
+
+func TestClaimManifestErrors(t *testing.T) {
+	srv := setupManifestServer(t)
+
+	for path, code := range map[string]int{
+		"/api/v1/claims/nonexistent/manifest":      http.StatusNotFound,
+		"/api/v1/claims/demo-project/manifest":     http.StatusNotFound,
+		"/api/v1/claims/hacky/manifest?format=x":   http.StatusNotAcceptable,
+		"/api/v1/claims/hacky/manifest?format=csv": http.StatusNotAcceptable,
+	} {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, code, rr.Code, path)
+	}
+}
+
+func TestListClaimsExpandManifest(t *testing.T) {
+	srv := setupManifestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?expand=manifest&sort=name", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp ExpandedClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "ClaimList", resp.Kind)
+	require.Len(t, resp.Items, 3)
+	for _, item := range resp.Items {
+		if item.Name == "hacky" {
+			assert.Equal(t, "VolumeClaim", item.Manifest.(map[string]any)["kind"])
+			assert.Empty(t, item.ManifestError)
+		} else {
+			assert.Nil(t, item.Manifest, item.Name)
+			assert.Contains(t, item.ManifestError, "manifest not found", item.Name)
+		}
+	}
+
+	// Continue tokens are independent of expand.
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?limit=1", nil))
+	var page ClaimListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?limit=1&expand=manifest&continue="+page.Metadata.Continue, nil))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?expand=parameters", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestListClaimsParamFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testRegistryYAML))
+	}))
+	t.Cleanup(ts.Close)
+
+	crawler := isync.NewParamCrawler(isync.ParamCrawlerConfig{Fetcher: newManifestUpstream(t)})
+	registries := isync.NewFederation(isync.FederationConfig{Crawler: crawler},
+		isync.NewSyncer(isync.Config{Repo: "test/repo", BaseURL: ts.URL}))
+	require.NoError(t, registries.InitialSync(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() { cancel(); registries.Stop() })
+	registries.Start(ctx)
+	require.Eventually(t, func() bool {
+		e, _ := registries.Snapshot().Get("hacky")
+		return e.Parameters != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	srv := NewServer(registries, Config{})
+	for query, want := range map[string][]string{
+		"param.cpu=4":                           {"hacky"},
+		"param.cpu=2":                           nil,
+		"param.disk.size=20Gi&category=cli":     {"hacky"},
+		"fieldSelector=!param.cpu&sort=name":    {"demo-project", "harvestervm-developer-martin"},
+		"fieldSelector=param.cpu+in+(2,4)":      {"hacky"},
+		"sort=-param.cpu,name&param.disk.size=": {"hacky", "demo-project", "harvestervm-developer-martin"},
+	} {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code, query)
+
+		var resp ClaimListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		var names []string
+		for _, item := range resp.Items {
+			names = append(names, item.Name)
+		}
+		assert.Equal(t, want, names, query)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky", nil))
+	assert.Contains(t, rr.Body.String(), `"parameters":{"cpu":4,"disk":{"size":"20Gi"}}`)
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?fields=name,param.cpu", nil))
+	assert.JSONEq(t, `{"name":"hacky","parameters":{"cpu":4}}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?param.=4", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
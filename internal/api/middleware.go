@@ -4,14 +4,27 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer returns the package tracer from the current global provider, so
+// that spans follow a provider installed after the package was loaded.
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/stuttgart-things/machinery-registry-api/internal/api")
+}
+
 // responseRecorder wraps http.ResponseWriter to capture status and size
 type responseRecorder struct {
 	http.ResponseWriter
@@ -33,6 +46,12 @@ func (rw *responseRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController, e.g. for
+// flushing watch streams.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // context key type to avoid collisions
 type ctxKey string
 
@@ -54,6 +73,7 @@ func requestIDMiddleware(next http.Handler) http.Handler {
 			rid = newRequestID()
 		}
 		w.Header().Set("X-Request-ID", rid)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", rid))
 
 		ctx := context.WithValue(r.Context(), ctxRequestIDKey, rid)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -61,47 +81,104 @@ func requestIDMiddleware(next http.Handler) http.Handler {
 }
 
 // loggingMiddleware logs all HTTP requests
-func loggingMiddleware(next http.Handler) http.Handler {
+func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		rec := &responseRecorder{ResponseWriter: w}
 
 		next.ServeHTTP(rec, r)
 
-		duration := time.Since(start)
-		remote := r.RemoteAddr
-		ua := r.UserAgent()
-		reqID, _ := r.Context().Value(ctxRequestIDKey).(string)
-
-		if os.Getenv("LOG_FORMAT") == "json" {
-			entry := map[string]interface{}{
-				"ts":        time.Now().Format(time.RFC3339Nano),
-				"method":    r.Method,
-				"path":      r.RequestURI,
-				"status":    rec.status,
-				"bytes":     rec.size,
-				"duration":  duration.String(),
-				"remote":    remote,
-				"ua":        ua,
-				"requestId": reqID,
-			}
-			b, _ := json.Marshal(entry)
-			log.Println(string(b))
-			return
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.RequestURI),
+			slog.String(logging.KeyRoute, routeTemplate(r)),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.size),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+			slog.String("ua", r.UserAgent()),
+		}
+		attrs = append(attrs, requestAttrs(r)...)
+		s.log.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// requestAttrs returns the request ID and trace ID of r, when known.
+func requestAttrs(r *http.Request) []slog.Attr {
+	var attrs []slog.Attr
+	if rid, _ := r.Context().Value(ctxRequestIDKey).(string); rid != "" {
+		attrs = append(attrs, slog.String(logging.KeyRequestID, rid))
+	}
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		attrs = append(attrs, slog.String(logging.KeyTraceID, sc.TraceID().String()))
+	}
+	return attrs
+}
+
+// metricsMiddleware records request count, latency and in-flight requests.
+// Routes are labeled by their mux path template (e.g. /api/v1/claims/{name})
+// so that label cardinality does not grow with request paths.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		route := routeTemplate(r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		code := strconv.Itoa(rec.status)
+
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, code).Inc()
+		metrics.HTTPDuration.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the mux path template of the route matched for r.
+// Router middleware only runs for matched routes, so requests that match no
+// route are not counted, traced or logged.
+func routeTemplate(r *http.Request) string {
+	if cur := mux.CurrentRoute(r); cur != nil {
+		if tpl, err := cur.GetPathTemplate(); err == nil {
+			return tpl
 		}
+	}
+	return ""
+}
+
+// untracedRoutes are probe and scrape endpoints excluded from tracing.
+var untracedRoutes = map[string]bool{"/health": true, "/ready": true, "/metrics": true}
 
-		log.Printf("%s %s -> %d (%s) reqId=%s remote=%s ua=%q bytes=%d",
-			r.Method, r.RequestURI, rec.status, duration, reqID, remote, ua, rec.size)
+// tracingMiddleware starts a server span per request, continuing the trace
+// from an incoming W3C traceparent header. Spans are named after the route
+// template, e.g. "GET /api/v1/claims/{name}".
+func tracingMiddleware(next http.Handler) http.Handler {
+	route := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.route", routeTemplate(r)))
+		next.ServeHTTP(w, r)
 	})
+	return otelhttp.NewHandler(route, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + routeTemplate(r)
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !untracedRoutes[routeTemplate(r)]
+		}),
+	)
 }
 
 // corsMiddleware adds CORS headers to allow cross-origin requests
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
-		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Total-Items, X-Continue, X-Resource-Version")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -113,7 +190,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 }
 
 // errorHandlerMiddleware handles panics
-func errorHandlerMiddleware(next http.Handler) http.Handler {
+func (s *Server) errorHandlerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -122,24 +199,14 @@ func errorHandlerMiddleware(next http.Handler) http.Handler {
 					rid = w.Header().Get("X-Request-ID")
 				}
 
-				if os.Getenv("LOG_FORMAT") == "json" {
-					entry := map[string]interface{}{
-						"ts":        time.Now().Format(time.RFC3339Nano),
-						"level":     "error",
-						"event":     "panic",
-						"error":     fmt.Sprintf("%v", err),
-						"method":    r.Method,
-						"path":      r.RequestURI,
-						"remote":    r.RemoteAddr,
-						"ua":        r.UserAgent(),
-						"requestId": rid,
-					}
-					b, _ := json.Marshal(entry)
-					log.Println(string(b))
-				} else {
-					log.Printf("Panic recovered: %v reqId=%s method=%s path=%s",
-						err, rid, r.Method, r.RequestURI)
-				}
+				s.log.LogAttrs(r.Context(), slog.LevelError, "panic recovered",
+					slog.Any(logging.KeyError, err),
+					slog.String("method", r.Method),
+					slog.String("path", r.RequestURI),
+					slog.String("remote", r.RemoteAddr),
+					slog.String("ua", r.UserAgent()),
+					slog.String(logging.KeyRequestID, rid),
+				)
 
 				w.Header().Set("Content-Type", "application/json")
 				if rid != "" {
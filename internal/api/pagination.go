@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// listCursor is the decoded form of the opaque continue token. It pins the
+// snapshot the listing started on and the query it was issued for.
+type listCursor struct {
+	Version string `json:"v"` // Snapshot version of the first page
+	Query   string `json:"q"` // Hash of the filter and sort parameters
+	Offset  int    `json:"o"` // Index of the first item of the next page
+}
+
+var (
+	errInvalidContinue = errors.New("invalid continue token")
+	errContinueQuery   = errors.New("continue token was issued for a different query")
+)
+
+// encode returns the opaque token form of the cursor.
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a continue token.
+func decodeCursor(token string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, errInvalidContinue
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return c, errInvalidContinue
+	}
+	return c, nil
+}
+
+// queryHash fingerprints the parameters that determine the result set, so a
+// continue token cannot be replayed against a different filter or order.
+// limit, expand, fields and the output format may change between pages.
+func queryHash(q url.Values) string {
+	params := url.Values{}
+	for k, v := range q {
+		switch k {
+		case "limit", "continue", "expand", "fields", "format", "columns":
+		default:
+			params[k] = v
+		}
+	}
+	sum := sha256.Sum256([]byte(params.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// parseLimit parses the limit query parameter; zero means unlimited.
+func parseLimit(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return n, nil
+}
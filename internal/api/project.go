@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// ProjectedClaimListResponse wraps claims reduced to the fields query
+// parameter for the list endpoint
+type ProjectedClaimListResponse struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Metadata   ListMeta             `json:"metadata"`
+	Items      []registry.ClaimView `json:"items"`
+}
+
+// ProjectedHit is a search hit whose claim is reduced to the fields query
+// parameter; highlights of other fields are dropped
+type ProjectedHit struct {
+	Score      float64            `json:"score"`
+	Claim      registry.ClaimView `json:"claim"`
+	Highlights map[string]string  `json:"highlights"`
+}
+
+// ProjectedSearchResponse wraps projected hits for the search endpoint
+type ProjectedSearchResponse struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Query      string         `json:"query"`
+	Items      []ProjectedHit `json:"items"`
+}
+
+// manifestFields are the fields expand=manifest adds to a claim.
+type manifestFields struct {
+	Manifest      any    `json:"manifest,omitempty"`
+	ManifestError string `json:"manifestError,omitempty"`
+}
+
+// parseProjection parses the fields query parameter; nil selects every
+// field.
+func parseProjection(r *http.Request) (*registry.Projection, error) {
+	return registry.ParseProjection(r.URL.Query().Get("fields"))
+}
+
+// projectClaim reduces e to the fields of p, or returns it unchanged if p
+// is nil.
+func projectClaim(p *registry.Projection, e *registry.ClaimEntry) any {
+	if p == nil {
+		return e
+	}
+	return p.View(e)
+}
+
+// projectClaims reduces items to the fields of p.
+func projectClaims(p *registry.Projection, items []registry.ClaimEntry) []registry.ClaimView {
+	views := make([]registry.ClaimView, len(items))
+	for i := range items {
+		views[i] = p.View(&items[i])
+	}
+	return views
+}
+
+// projectExpanded reduces the claim fields of items to those of p; the
+// manifest fields are kept.
+func projectExpanded(p *registry.Projection, items []ExpandedClaim) []registry.ClaimView {
+	views := make([]registry.ClaimView, len(items))
+	for i := range items {
+		views[i] = p.View(&items[i].ClaimEntry)
+		views[i].Extra = manifestFields{Manifest: items[i].Manifest, ManifestError: items[i].ManifestError}
+	}
+	return views
+}
+
+// projectHits reduces the claims and highlights of hits to the fields of p.
+func projectHits(p *registry.Projection, hits []registry.Hit) []ProjectedHit {
+	projected := make([]ProjectedHit, len(hits))
+	for i := range hits {
+		highlights := make(map[string]string, len(hits[i].Highlights))
+		for field, h := range hits[i].Highlights {
+			if p.Includes(field) {
+				highlights[field] = h
+			}
+		}
+		projected[i] = ProjectedHit{Score: hits[i].Score, Claim: p.View(&hits[i].Claim), Highlights: highlights}
+	}
+	return projected
+}
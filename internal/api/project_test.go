@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClaimsFields(t *testing.T) {
+	srv := setupTestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?fields=status,name,template&sort=name&limit=2", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp struct {
+		Kind     string           `json:"kind"`
+		Metadata ListMeta         `json:"metadata"`
+		Items    []map[string]any `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "ClaimList", resp.Kind)
+	assert.Equal(t, 3, resp.Metadata.TotalItems)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, map[string]any{"name": "demo-project", "template": "harborproject", "status": "inactive"}, resp.Items[0])
+	assert.Contains(t, rr.Body.String(), `{"name":"demo-project","template":"harborproject","status":"inactive"}`)
+
+	// Continue tokens are independent of fields.
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?sort=name&limit=2&continue="+resp.Metadata.Continue, nil))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// NDJSON lines are projected as well.
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?fields=name&sort=name&format=ndjson", nil))
+	assert.Equal(t, "{\"name\":\"demo-project\"}\n{\"name\":\"hacky\"}\n{\"name\":\"harvestervm-developer-martin\"}\n", rr.Body.String())
+
+	for target, want := range map[string]string{
+		"/api/v1/claims?fields=name,owner":      `unknown field \"owner\"`,
+		"/api/v1/claims?fields=name&format=csv": "fields is not supported with CSV output, use columns",
+	} {
+		rr = httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+		assert.Contains(t, rr.Body.String(), want, target)
+	}
+}
+
+func TestListClaimsFieldsExpand(t *testing.T) {
+	srv := setupManifestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims?fields=name&expand=manifest&category=infra", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"items":[{"name":"demo-project","manifestError":`)
+}
+
+func TestGetClaimFields(t *testing.T) {
+	srv := setupTestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?fields=name,createdAt", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"name":"hacky","createdAt":"2026-02-05T10:58:33Z"}`, rr.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?fields=name,status", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Equal(t, "name: hacky\nstatus: active\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?fields=", nil))
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entry))
+	assert.Len(t, entry, 11, "empty fields selects every field")
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/claims/hacky?fields=Name", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestSearchFields(t *testing.T) {
+	srv := setupTestServer(t)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/search?q=hacky&fields=name,template", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var raw struct {
+		Items []struct {
+			Claim      map[string]any    `json:"claim"`
+			Highlights map[string]string `json:"highlights"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &raw))
+	require.NotEmpty(t, raw.Items)
+	assert.Equal(t, map[string]any{"name": "hacky", "template": "volumeclaim"}, raw.Items[0].Claim)
+	assert.Contains(t, raw.Items[0].Highlights, "name")
+	assert.NotContains(t, raw.Items[0].Highlights, "path", "highlights of other fields are dropped")
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/search?q=hacky&fields=owner", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -3,29 +3,62 @@ package api
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
 	"github.com/stuttgart-things/machinery-registry-api/internal/sync"
 	"github.com/stuttgart-things/machinery-registry-api/internal/version"
 )
 
+// Config holds optional HTTP server settings
+type Config struct {
+	WebhookSecret string       // Secret for verifying GitHub webhook signatures
+	SyncToken     string       // Bearer token required by POST /api/v1/sync
+	Logger        *slog.Logger // Defaults to slog.Default()
+
+	// Manifests fetches the claim manifests referenced by entries; defaults
+	// to a fetcher reading from GitHub.
+	Manifests *manifest.Fetcher
+
+	// CSVColumns are the fields of CSV output without a columns query
+	// parameter; defaults to every claim field.
+	CSVColumns []string
+}
+
 // Server represents the HTTP API server
 type Server struct {
-	router *mux.Router
-	http   *http.Server
-	syncer *sync.Syncer
+	cfg        Config
+	router     *mux.Router
+	http       *http.Server
+	registries *sync.Federation
+	log        *slog.Logger
+	done       chan struct{} // closed on shutdown to end watch streams
 }
 
 // NewServer creates and initializes a new HTTP server
-func NewServer(syncer *sync.Syncer) *Server {
+func NewServer(registries *sync.Federation, cfg Config) *Server {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Manifests == nil {
+		cfg.Manifests = manifest.NewFetcher(manifest.Config{})
+	}
+	if len(cfg.CSVColumns) == 0 {
+		cfg.CSVColumns = registry.FieldNames()
+	}
 	s := &Server{
-		router: mux.NewRouter(),
-		syncer: syncer,
+		cfg:        cfg,
+		router:     mux.NewRouter(),
+		registries: registries,
+		log:        cfg.Logger,
+		done:       make(chan struct{}),
 	}
 
 	s.registerRoutes()
@@ -43,6 +76,7 @@ func NewServer(syncer *sync.Syncer) *Server {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	s.http.RegisterOnShutdown(func() { close(s.done) })
 
 	return s
 }
@@ -50,33 +84,46 @@ func NewServer(syncer *sync.Syncer) *Server {
 // registerRoutes sets up all API routes
 func (s *Server) registerRoutes() {
 	s.router.HandleFunc("/health", s.healthCheck).Methods(http.MethodGet)
+	s.router.HandleFunc("/ready", s.readinessCheck).Methods(http.MethodGet)
 	s.router.HandleFunc("/", s.rootInfo).Methods(http.MethodGet)
 	s.router.HandleFunc("/version", s.versionInfo).Methods(http.MethodGet)
 	s.router.HandleFunc("/openapi", s.serveOpenAPI).Methods(http.MethodGet)
 	s.router.HandleFunc("/openapi.yaml", s.serveOpenAPI).Methods(http.MethodGet)
 	s.router.HandleFunc("/docs", s.serveDocs).Methods(http.MethodGet)
+	s.router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
 
 	s.router.HandleFunc("/api/v1/claims", s.listClaims).Methods(http.MethodGet)
 	s.router.HandleFunc("/api/v1/claims/{name}", s.getClaim).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/claims/{name}/history", s.claimHistory).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/claims/{name}/manifest", s.claimManifest).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/changes", s.listChanges).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/search", s.searchClaims).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/stats", s.claimStats).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/watch", s.watchClaims).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/sync/status", s.syncStatus).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/sync", s.forceSync).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/v1/hooks/github", s.githubWebhook).Methods(http.MethodPost)
 }
 
 // applyMiddleware applies middleware to all routes
 func (s *Server) applyMiddleware() {
-	s.router.Use(errorHandlerMiddleware)
+	s.router.Use(s.errorHandlerMiddleware)
+	s.router.Use(metricsMiddleware)
+	s.router.Use(tracingMiddleware)
 	s.router.Use(corsMiddleware)
 	s.router.Use(requestIDMiddleware)
-	s.router.Use(loggingMiddleware)
+	s.router.Use(s.loggingMiddleware)
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	log.Printf("HTTP API server starting on %s", s.http.Addr)
+	s.log.Info("HTTP API server starting", "addr", s.http.Addr)
 	return s.http.ListenAndServe()
 }
 
 // Stop gracefully stops the HTTP server
 func (s *Server) Stop(ctx context.Context) error {
-	log.Println("Shutting down HTTP server...")
+	s.log.Info("shutting down HTTP server")
 	return s.http.Shutdown(ctx)
 }
 
@@ -87,6 +134,25 @@ func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
 }
 
+// readinessCheck reports ready while at least one registry snapshot is loaded
+// and its last successful sync is within the staleness threshold, or it is
+// served from the snapshot cache; registries that are not are listed as
+// degraded
+func (s *Server) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	st := s.registries.Status()
+
+	status, code := "ready", http.StatusOK
+	if !st.Ready() {
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, map[string]interface{}{
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+		"sync":      st,
+	})
+}
+
 // rootInfo returns a minimal service index
 func (s *Server) rootInfo(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -96,9 +162,20 @@ func (s *Server) rootInfo(w http.ResponseWriter, r *http.Request) {
   "version": "%s",
   "endpoints": [
     "/health",
+    "/ready",
     "/version",
+    "/metrics",
     "/api/v1/claims",
     "/api/v1/claims/{name}",
+    "/api/v1/claims/{name}/history",
+    "/api/v1/claims/{name}/manifest",
+    "/api/v1/changes",
+    "/api/v1/search",
+    "/api/v1/stats",
+    "/api/v1/watch",
+    "/api/v1/sync/status",
+    "/api/v1/sync",
+    "/api/v1/hooks/github",
     "/openapi.yaml",
     "/docs"
   ]
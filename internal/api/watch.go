@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	"github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// watchHeartbeat is the interval of SSE comment lines keeping idle watch
+// connections open through proxies.
+const watchHeartbeat = 30 * time.Second
+
+// sseWriter writes Server-Sent Events. The first write error sticks and
+// ends the stream.
+type sseWriter struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	err error
+	id  string // last event ID sent to the client
+}
+
+// event writes one SSE event carrying v as JSON.
+func (sw *sseWriter) event(id, name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		sw.err = err
+		return
+	}
+	sw.write("id: %s\nevent: %s\ndata: %s\n\n", id, name, data)
+	sw.id = id
+}
+
+// advance moves the client's last event ID to id without dispatching an
+// event, so that events filtered out by the selector are not replayed when
+// the client reconnects, and flushes the stream.
+func (sw *sseWriter) advance(id string) {
+	if id != sw.id {
+		sw.write("id: %s\n\n", id)
+		sw.id = id
+	}
+	sw.flush()
+}
+
+func (sw *sseWriter) write(format string, args ...any) {
+	if sw.err == nil {
+		_, sw.err = fmt.Fprintf(sw.w, format, args...)
+	}
+}
+
+func (sw *sseWriter) flush() {
+	if sw.err == nil {
+		sw.err = sw.rc.Flush()
+	}
+}
+
+// watchClaims streams claim changes as Server-Sent Events. Each event is
+// named after its type (ADDED, MODIFIED, DELETED) and carries
+// {"type", "resourceVersion", "object"}. Without a resource version the
+// stream starts with an ADDED event per current claim. The Last-Event-ID
+// header, or the resourceVersion query parameter, resumes after that event;
+// 410 Gone means it is no longer retained and the client must re-list.
+// listClaims filters apply to the streamed claims.
+func (s *Server) watchClaims(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selector, err := listSelector(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap, rv := s.registries.Current()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "registry not yet loaded")
+		return
+	}
+
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = query.Get("resourceVersion")
+	}
+	var initial []registry.ClaimEntry
+	if resume == "" {
+		initial = snap.Select(selector)
+	} else {
+		if _, _, err := s.registries.Events().Since(resume); err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, sync.ErrResourceVersionExpired) {
+				code = http.StatusGone
+			}
+			writeError(w, code, err.Error())
+			return
+		}
+		rv = resume
+	}
+
+	rc := http.NewResponseController(w)
+	// Watches outlive the server's write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &sseWriter{w: w, rc: rc}
+	for _, e := range initial {
+		sw.event(rv, string(registry.Added), sync.Event{Type: registry.Added, ResourceVersion: rv, Object: e})
+	}
+	sw.advance(rv)
+
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+
+	for sw.err == nil {
+		events, wait, err := s.registries.Events().Since(rv)
+		if err != nil {
+			// The client fell behind the retained history.
+			sw.event(rv, "ERROR", map[string]string{"type": "ERROR", "error": err.Error()})
+			sw.flush()
+			return
+		}
+		for _, ev := range events {
+			rv = ev.ResourceVersion
+			if selector.Matches(&ev.Object) {
+				sw.event(rv, string(ev.Type), ev)
+			}
+		}
+		sw.advance(rv)
+
+		select {
+		case <-wait:
+		case <-heartbeat.C:
+			sw.write(": heartbeat\n\n")
+			sw.flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
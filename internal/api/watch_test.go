@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// setupLiveServer serves the router over HTTP with a running sync loop.
+// The returned function replaces the registry content and waits for the
+// change to be merged.
+func setupLiveServer(t *testing.T) (*httptest.Server, func(body string)) {
+	t.Helper()
+
+	var (
+		mu   gosync.Mutex
+		body = testRegistryYAML
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(upstream.Close)
+
+	syncer := isync.NewSyncer(isync.Config{Repo: "test/repo", BaseURL: upstream.URL, Interval: time.Hour})
+	registries := isync.NewFederation(isync.FederationConfig{}, syncer)
+	require.NoError(t, registries.InitialSync(context.Background()))
+	registries.Start(context.Background())
+	t.Cleanup(registries.Stop)
+
+	srv := NewServer(registries, Config{})
+	ts := httptest.NewServer(srv.router)
+	t.Cleanup(ts.Close)
+
+	update := func(b string) {
+		mu.Lock()
+		body = b
+		mu.Unlock()
+		_, before := registries.Current()
+		registries.Trigger()
+		require.Eventually(t, func() bool {
+			_, rv := registries.Current()
+			return rv != before
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	return ts, update
+}
+
+type sseEvent struct {
+	id, name string
+	data     isync.Event
+}
+
+// openWatch starts a watch request and returns a reader for its events.
+func openWatch(t *testing.T, url, lastEventID string) (*http.Response, func() sseEvent) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	sc := bufio.NewScanner(resp.Body)
+	next := func() sseEvent {
+		t.Helper()
+		var ev sseEvent
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if ev.name != "" {
+					return ev
+				}
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				ev.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev.data))
+			}
+		}
+		t.Fatalf("watch stream ended: %v", sc.Err())
+		return ev
+	}
+	return resp, next
+}
+
+const updatedRegistryYAML = `
+apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    status: inactive
+  - name: harvestervm-developer-martin
+    template: harvestervm
+    category: cli
+    namespace: default
+    createdAt: "2026-02-05T17:57:18Z"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/harvestervm-developer-martin.yaml
+    status: active
+  - name: new-claim
+    template: volumeclaim
+    category: cli
+    status: active
+`
+
+func TestWatchStreamsChanges(t *testing.T) {
+	ts, update := setupLiveServer(t)
+
+	resp, next := openWatch(t, ts.URL+"/api/v1/claims?watch=true&template=volumeclaim", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	initial := next()
+	assert.Equal(t, "ADDED", initial.name)
+	assert.Equal(t, "hacky", initial.data.Object.Name)
+
+	update(updatedRegistryYAML)
+
+	modified := next()
+	assert.Equal(t, "MODIFIED", modified.name)
+	assert.Equal(t, registry.Modified, modified.data.Type)
+	assert.Equal(t, "hacky", modified.data.Object.Name)
+	assert.Equal(t, "inactive", modified.data.Object.Status)
+	assert.Equal(t, modified.id, modified.data.ResourceVersion)
+
+	added := next()
+	assert.Equal(t, "ADDED", added.name)
+	assert.Equal(t, "new-claim", added.data.Object.Name)
+}
+
+func TestWatchResume(t *testing.T) {
+	ts, update := setupLiveServer(t)
+
+	var list ClaimListResponse
+	resp, err := http.Get(ts.URL + "/api/v1/claims")
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	resp.Body.Close()
+	require.NotEmpty(t, list.Metadata.ResourceVersion)
+
+	update(updatedRegistryYAML)
+
+	// Resuming from the list replays the changes made since, in order.
+	resp, next := openWatch(t, ts.URL+"/api/v1/watch", list.Metadata.ResourceVersion)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got []string
+	var last sseEvent
+	for range 3 {
+		last = next()
+		got = append(got, last.name+" "+last.data.Object.Name)
+	}
+	assert.Equal(t, []string{"MODIFIED hacky", "ADDED new-claim", "DELETED demo-project"}, got)
+
+	// Resuming from the last event sees nothing old, only new changes.
+	_, next = openWatch(t, ts.URL+"/api/v1/watch?resourceVersion="+last.id, "")
+	update(testRegistryYAML)
+	ev := next()
+	assert.Equal(t, "MODIFIED hacky", ev.name+" "+ev.data.Object.Name)
+}
+
+func TestWatchInvalidResourceVersion(t *testing.T) {
+	ts, _ := setupLiveServer(t)
+
+	for rv, code := range map[string]int{
+		"garbage":      http.StatusBadRequest,
+		"oldprocess-3": http.StatusGone,
+	} {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/watch", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", rv)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, code, resp.StatusCode, rv)
+	}
+}
@@ -0,0 +1,67 @@
+// Package logging builds the service's structured logger and defines the
+// attribute keys shared by all log records.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Attribute keys used consistently across log records.
+const (
+	KeyRequestID  = "requestId"
+	KeyTraceID    = "traceId"
+	KeyRoute      = "route"
+	KeyRegistry   = "registry"
+	KeyRevision   = "revision"
+	KeyClaimCount = "claimCount"
+	KeyError      = "error"
+)
+
+// ParseLevel parses debug, info, warn or error; empty selects info.
+func ParseLevel(v string) (slog.Level, error) {
+	switch strings.ToLower(v) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", v)
+	}
+}
+
+// New returns a logger writing to w. format is "json" or "text" (the
+// default); level is as accepted by ParseLevel.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected text or json)", format)
+	}
+}
+
+// FromEnv returns a stderr logger configured by LOG_FORMAT and LOG_LEVEL.
+func FromEnv() (*slog.Logger, error) {
+	return New(os.Stderr, os.Getenv("LOG_FORMAT"), os.Getenv("LOG_LEVEL"))
+}
+
+// Err returns the attribute for err under KeyError.
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
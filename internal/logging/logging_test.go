@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := ParseLevel(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.ErrorContains(t, err, `unknown log level "verbose"`)
+}
+
+func TestNewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "warn")
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("sync failed", KeyRegistry, "main", Err(errors.New("boom")))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "sync failed", entry["msg"])
+	assert.Equal(t, "main", entry[KeyRegistry])
+	assert.Equal(t, "boom", entry[KeyError])
+}
+
+func TestNewText(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "", "")
+	require.NoError(t, err)
+
+	logger.Debug("dropped")
+	logger.Info("sync complete", KeyClaimCount, 3)
+	assert.Contains(t, buf.String(), "msg=\"sync complete\" claimCount=3")
+	assert.NotContains(t, buf.String(), "dropped")
+}
+
+func TestNewInvalidFormat(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", "")
+	assert.ErrorContains(t, err, `unknown log format "xml"`)
+}
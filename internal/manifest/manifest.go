@@ -0,0 +1,277 @@
+// Package manifest fetches the claim manifests referenced by registry
+// entries and caches them.
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotFound is returned when the referenced file does not exist.
+	ErrNotFound = errors.New("manifest not found")
+	// ErrInvalidReference is returned for repositories and paths that do not
+	// identify a file in a GitHub repository.
+	ErrInvalidReference = errors.New("invalid manifest reference")
+)
+
+// Manifest is a fetched claim manifest.
+type Manifest struct {
+	Repository string    // GitHub repo slug
+	Path       string    // File path in the repository
+	Revision   string    // ETag of the fetched content
+	FetchedAt  time.Time // When the content was fetched or last revalidated
+	Data       []byte    // Raw YAML
+}
+
+// Parse decodes the manifest YAML into JSON-compatible values. A single
+// document yields its value, several documents a list of values.
+func (m *Manifest) Parse() (any, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(m.Data))
+	var docs []any
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", m.Path, err)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 1 {
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// Config holds fetcher configuration.
+type Config struct {
+	BaseURL string        // Raw content base URL; defaults to https://raw.githubusercontent.com
+	Ref     string        // Git ref manifests are read from; defaults to HEAD (the default branch)
+	Token   string        // GitHub token (optional, for private repos)
+	TTL     time.Duration // How long fetched manifests are served without revalidation; defaults to 5m
+	MaxSize int64         // Largest accepted manifest in bytes; defaults to 1 MiB
+	Entries int           // Cached manifests; the least recently fetched are evicted first; defaults to 10000
+	Client  *http.Client  // Defaults to a traced client
+}
+
+// Fetcher reads manifests from GitHub. Results, including missing files,
+// are cached for the TTL; expired entries are revalidated with their ETag.
+// Concurrent requests for the same manifest share one upstream fetch. It is
+// safe for concurrent use.
+type Fetcher struct {
+	cfg    Config
+	flight singleflight.Group
+	mu     sync.Mutex
+	cache  map[string]*entry
+}
+
+type entry struct {
+	manifest *Manifest
+	err      error // cached ErrNotFound
+	expires  time.Time
+}
+
+// NewFetcher creates a Fetcher with the given configuration.
+func NewFetcher(cfg Config) *Fetcher {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://raw.githubusercontent.com"
+	}
+	if cfg.Ref == "" {
+		cfg.Ref = "HEAD"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 1 << 20
+	}
+	if cfg.Entries <= 0 {
+		cfg.Entries = 10000
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
+	return &Fetcher{cfg: cfg, cache: map[string]*entry{}}
+}
+
+// repoSlug matches GitHub "owner/name" slugs.
+var repoSlug = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// Reference normalizes a claim's repository and path. The repository may
+// be a slug or a github.com URL; the path is resolved inside the repository.
+func Reference(repository, file string) (string, string, error) {
+	repo := strings.TrimSuffix(repository, ".git")
+	for _, prefix := range []string{"https://github.com/", "http://github.com/", "github.com/"} {
+		repo = strings.TrimPrefix(repo, prefix)
+	}
+	repo = strings.Trim(repo, "/")
+	if !repoSlug.MatchString(repo) {
+		return "", "", fmt.Errorf("%w: repository %q", ErrInvalidReference, repository)
+	}
+
+	p := path.Clean("/" + file)
+	if p == "/" {
+		return "", "", fmt.Errorf("%w: path %q", ErrInvalidReference, file)
+	}
+	return repo, strings.TrimPrefix(p, "/"), nil
+}
+
+// Get returns the manifest at file in repository, from cache while fresh.
+func (f *Fetcher) Get(ctx context.Context, repository, file string) (*Manifest, error) {
+	repo, file, err := Reference(repository, file)
+	if err != nil {
+		return nil, err
+	}
+	key := repo + "/" + file
+
+	if e := f.fresh(key, time.Now()); e != nil {
+		return e.manifest, e.err
+	}
+
+	// The shared fetch outlives callers that give up waiting for it.
+	ch := f.flight.DoChan(key, func() (any, error) {
+		return f.refresh(context.WithoutCancel(ctx), repo, file, key)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		e := res.Val.(*entry)
+		return e.manifest, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fresh returns the cache entry for key if it has not expired at now.
+func (f *Fetcher) fresh(key string, now time.Time) *entry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if e := f.cache[key]; e != nil && now.Before(e.expires) {
+		return e
+	}
+	return nil
+}
+
+// refresh fetches a manifest, revalidating an expired cache entry, and
+// caches the result.
+func (f *Fetcher) refresh(ctx context.Context, repo, file, key string) (*entry, error) {
+	now := time.Now()
+	f.mu.Lock()
+	cached := f.cache[key]
+	f.mu.Unlock()
+	if cached != nil && now.Before(cached.expires) {
+		return cached, nil
+	}
+
+	var etag string
+	if cached != nil && cached.manifest != nil {
+		etag = cached.manifest.Revision
+	}
+	m, err := f.fetch(ctx, repo, file, etag)
+	switch {
+	case errors.Is(err, errNotModified):
+		m, err = &Manifest{
+			Repository: repo,
+			Path:       file,
+			Revision:   etag,
+			FetchedAt:  now,
+			Data:       cached.manifest.Data,
+		}, nil
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	e := &entry{manifest: m, err: err, expires: now.Add(f.cfg.TTL)}
+	f.mu.Lock()
+	f.cache[key] = e
+	f.evict()
+	f.mu.Unlock()
+	return e, nil
+}
+
+// evict drops the entries expiring first, i.e. those fetched longest ago,
+// once the cache exceeds the configured size. It frees a tenth of the
+// entries at a time, so that a full cache is not sorted on every insert.
+// The caller holds f.mu.
+func (f *Fetcher) evict() {
+	if len(f.cache) <= f.cfg.Entries {
+		return
+	}
+	keys := slices.Collect(maps.Keys(f.cache))
+	slices.SortFunc(keys, func(a, b string) int {
+		return f.cache[a].expires.Compare(f.cache[b].expires)
+	})
+	for _, k := range keys[:len(keys)-f.cfg.Entries+f.cfg.Entries/10] {
+		delete(f.cache, k)
+	}
+}
+
+// errNotModified is returned by fetch for 304 responses.
+var errNotModified = errors.New("not modified")
+
+// fetch downloads a manifest, conditionally when etag is set.
+func (f *Fetcher) fetch(ctx context.Context, repo, file, etag string) (*Manifest, error) {
+	u := strings.TrimRight(f.cfg.BaseURL, "/") + "/" + repo + "/" + url.PathEscape(f.cfg.Ref) + "/" + (&url.URL{Path: file}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.cfg.Token != "" {
+		req.Header.Set("Authorization", "token "+f.cfg.Token)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := f.cfg.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, errNotModified
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, repo, file)
+	default:
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, u)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if int64(len(data)) > f.cfg.MaxSize {
+		return nil, fmt.Errorf("manifest %s/%s exceeds %d bytes", repo, file, f.cfg.MaxSize)
+	}
+	return &Manifest{
+		Repository: repo,
+		Path:       file,
+		Revision:   resp.Header.Get("ETag"),
+		FetchedAt:  time.Now(),
+		Data:       data,
+	}, nil
+}
@@ -0,0 +1,237 @@
+package manifest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifestYAML = `apiVersion: resources.stuttgart-things.com/v1alpha1
+kind: VolumeClaim
+metadata:
+  name: hacky
+spec:
+  size: 10Gi
+`
+
+// upstream serves files by path with ETag support and counts requests.
+type upstream struct {
+	*httptest.Server
+	mu          gosync.Mutex
+	files       map[string]string
+	requests    int
+	revalidated int
+}
+
+func newUpstream(t *testing.T, files map[string]string) *upstream {
+	t.Helper()
+	u := &upstream{files: files}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		u.requests++
+		body, ok := u.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		etag := `"` + r.URL.Path + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			u.revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func (u *upstream) counts() (int, int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.requests, u.revalidated
+}
+
+func TestReference(t *testing.T) {
+	tests := []struct {
+		repo, path         string
+		wantRepo, wantPath string
+		wantErr            bool
+	}{
+		{"stuttgart-things/harvester", "claims/cli/hacky.yaml", "stuttgart-things/harvester", "claims/cli/hacky.yaml", false},
+		{"https://github.com/stuttgart-things/harvester.git", "/claims/hacky.yaml", "stuttgart-things/harvester", "claims/hacky.yaml", false},
+		{"github.com/stuttgart-things/harvester/", "claims/../hacky.yaml", "stuttgart-things/harvester", "hacky.yaml", false},
+		{"stuttgart-things/harvester", "../../other/repo/secret.yaml", "stuttgart-things/harvester", "other/repo/secret.yaml", false},
+		{"harvester", "claims/hacky.yaml", "", "", true},
+		{"https://gitlab.com/a/b", "claims/hacky.yaml", "", "", true},
+		{"stuttgart-things/harvester", "", "", "", true},
+	}
+	for _, tt := range tests {
+		repo, path, err := Reference(tt.repo, tt.path)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidReference, tt.repo+" "+tt.path)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.wantRepo, repo)
+		assert.Equal(t, tt.wantPath, path)
+	}
+}
+
+func TestGetCachesAndRevalidates(t *testing.T) {
+	u := newUpstream(t, map[string]string{
+		"/stuttgart-things/harvester/HEAD/claims/cli/hacky.yaml": testManifestYAML,
+	})
+	f := NewFetcher(Config{BaseURL: u.URL, TTL: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	m, err := f.Get(ctx, "stuttgart-things/harvester", "claims/cli/hacky.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, testManifestYAML, string(m.Data))
+	assert.NotEmpty(t, m.Revision)
+
+	// Served from cache while fresh.
+	_, err = f.Get(ctx, "https://github.com/stuttgart-things/harvester", "/claims/cli/hacky.yaml")
+	require.NoError(t, err)
+	requests, _ := u.counts()
+	assert.Equal(t, 1, requests)
+
+	// Revalidated with the ETag once expired.
+	time.Sleep(60 * time.Millisecond)
+	again, err := f.Get(ctx, "stuttgart-things/harvester", "claims/cli/hacky.yaml")
+	require.NoError(t, err)
+	requests, revalidated := u.counts()
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, revalidated)
+	assert.Equal(t, m.Data, again.Data)
+	assert.Equal(t, m.Revision, again.Revision)
+	assert.True(t, again.FetchedAt.After(m.FetchedAt))
+}
+
+func TestGetNotFound(t *testing.T) {
+	u := newUpstream(t, map[string]string{})
+	f := NewFetcher(Config{BaseURL: u.URL})
+
+	for range 2 {
+		_, err := f.Get(context.Background(), "stuttgart-things/harvester", "claims/missing.yaml")
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	requests, _ := u.counts()
+	assert.Equal(t, 1, requests, "missing files are cached too")
+}
+
+func TestGetErrorsAreNotCached(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "/org/repo/release/claims/a.yaml", r.URL.Path)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	f := NewFetcher(Config{BaseURL: ts.URL, Ref: "release", Token: "secret"})
+
+	for range 2 {
+		_, err := f.Get(context.Background(), "org/repo", "claims/a.yaml")
+		assert.ErrorContains(t, err, "unexpected status 502")
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestGetEvictsOldestEntries(t *testing.T) {
+	u := newUpstream(t, map[string]string{
+		"/org/repo/HEAD/a.yaml": testManifestYAML,
+		"/org/repo/HEAD/b.yaml": testManifestYAML,
+		"/org/repo/HEAD/c.yaml": testManifestYAML,
+	})
+	f := NewFetcher(Config{BaseURL: u.URL, Entries: 2})
+	ctx := context.Background()
+
+	for _, file := range []string{"a.yaml", "b.yaml", "c.yaml"} {
+		_, err := f.Get(ctx, "org/repo", file)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond) // distinct expiry times
+	}
+	assert.Len(t, f.cache, 2)
+
+	// b and c are served from cache, a is fetched again.
+	for _, file := range []string{"b.yaml", "c.yaml", "a.yaml"} {
+		_, err := f.Get(ctx, "org/repo", file)
+		require.NoError(t, err)
+	}
+	requests, _ := u.counts()
+	assert.Equal(t, 4, requests)
+}
+
+func TestGetSharesConcurrentFetches(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Write([]byte(testManifestYAML))
+	}))
+	defer ts.Close()
+	f := NewFetcher(Config{BaseURL: ts.URL})
+
+	// A caller giving up does not abort the fetch the others wait for.
+	ctx, cancel := context.WithCancel(context.Background())
+	impatient := make(chan error)
+	go func() {
+		_, err := f.Get(ctx, "org/repo", "a.yaml")
+		impatient <- err
+	}()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-impatient, context.Canceled)
+
+	var wg gosync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			m, err := f.Get(context.Background(), "org/repo", "a.yaml")
+			if assert.NoError(t, err) {
+				assert.Equal(t, testManifestYAML, string(m.Data))
+			}
+		})
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetMaxSize(t *testing.T) {
+	u := newUpstream(t, map[string]string{"/org/repo/HEAD/big.yaml": testManifestYAML})
+	f := NewFetcher(Config{BaseURL: u.URL, MaxSize: 16})
+
+	_, err := f.Get(context.Background(), "org/repo", "big.yaml")
+	assert.ErrorContains(t, err, "exceeds 16 bytes")
+}
+
+func TestParse(t *testing.T) {
+	m := &Manifest{Path: "hacky.yaml", Data: []byte(testManifestYAML)}
+	v, err := m.Parse()
+	require.NoError(t, err)
+	obj, ok := v.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "VolumeClaim", obj["kind"])
+	assert.Equal(t, "10Gi", obj["spec"].(map[string]any)["size"])
+
+	m.Data = []byte("kind: A\n---\nkind: B\n")
+	v, err = m.Parse()
+	require.NoError(t, err)
+	assert.Len(t, v, 2)
+
+	m.Data = []byte("kind: [unclosed\n")
+	_, err = m.Parse()
+	assert.ErrorContains(t, err, "parsing hacky.yaml")
+}
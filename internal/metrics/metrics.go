@@ -0,0 +1,138 @@
+// Package metrics defines the Prometheus metrics exported on /metrics.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+const namespace = "machinery_registry"
+
+// Sync attempt results.
+const (
+	ResultChanged   = "changed"   // a new snapshot was swapped in
+	ResultUnchanged = "unchanged" // the source reported or served identical content
+	ResultInvalid   = "invalid"   // the content failed validation
+	ResultError     = "error"     // the fetch itself failed
+)
+
+// Notification delivery results.
+const (
+	ResultDelivered = "delivered" // the subscriber accepted the notification
+	ResultFailed    = "failed"    // the notification was dead-lettered
+)
+
+var (
+	// HTTPRequests counts handled requests by mux route template.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPDuration observes request latency by mux route template.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	// HTTPInFlight is the number of requests currently being served.
+	HTTPInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	// SyncAttempts counts sync attempts per registry and result.
+	SyncAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "attempts_total",
+		Help:      "Registry sync attempts by registry and result (changed, unchanged, invalid, error).",
+	}, []string{"registry", "result"})
+
+	// SyncFailures counts failed sync attempts (fetch errors and invalid content).
+	SyncFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "failures_total",
+		Help:      "Failed registry sync attempts by registry.",
+	}, []string{"registry"})
+
+	// SyncDuration observes how long sync attempts take.
+	SyncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "duration_seconds",
+		Help:      "Registry sync attempt duration by registry.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 4, 8),
+	}, []string{"registry"})
+
+	// SyncLastSuccess is the time of the last successful sync.
+	SyncLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful registry sync.",
+	}, []string{"registry"})
+
+	// SyncFetchedBytes counts registry bytes downloaded.
+	SyncFetchedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "fetched_bytes_total",
+		Help:      "Registry file bytes fetched by registry (not-modified responses fetch none).",
+	}, []string{"registry"})
+
+	// NotifyDeliveries counts webhook notifications by subscriber and result.
+	NotifyDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notify",
+		Name:      "deliveries_total",
+		Help:      "Webhook notifications by subscriber and result (delivered, failed).",
+	}, []string{"subscriber", "result"})
+
+	// Claims is the number of served claims by template and status.
+	Claims = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "claims",
+		Help:      "Claims in the served registry by template and status.",
+	}, []string{"template", "status"})
+)
+
+var (
+	claimsMu     sync.Mutex
+	claimsLabels = map[[2]string]bool{} // template and status of the series set by SetClaims
+)
+
+// SetClaims replaces the claims gauge with counts from entries. Current
+// series are updated in place and only vanished combinations are deleted,
+// so a concurrent scrape never sees the gauge empty.
+func SetClaims(entries []registry.ClaimEntry) {
+	groups := registry.GroupCounts(entries, []string{"template", "status"})
+
+	claimsMu.Lock()
+	defer claimsMu.Unlock()
+
+	labels := make(map[[2]string]bool, len(groups))
+	for _, g := range groups {
+		l := [2]string{g.Keys["template"], g.Keys["status"]}
+		labels[l] = true
+		Claims.WithLabelValues(l[0], l[1]).Set(float64(g.Count))
+	}
+	for l := range claimsLabels {
+		if !labels[l] {
+			Claims.DeleteLabelValues(l[0], l[1])
+		}
+	}
+	claimsLabels = labels
+}
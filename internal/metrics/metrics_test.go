@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+func TestSetClaims(t *testing.T) {
+	SetClaims([]registry.ClaimEntry{
+		{Name: "a", Template: "volumeclaim", Status: "active"},
+		{Name: "b", Template: "volumeclaim", Status: "active"},
+		{Name: "c", Template: "harvestervm", Status: "inactive"},
+	})
+	assert.Equal(t, 2, testutil.CollectAndCount(Claims))
+	assert.Equal(t, 2.0, testutil.ToFloat64(Claims.WithLabelValues("volumeclaim", "active")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(Claims.WithLabelValues("harvestervm", "inactive")))
+
+	// Combinations that disappear are dropped rather than left stale
+	SetClaims([]registry.ClaimEntry{{Name: "c", Template: "harvestervm", Status: "active"}})
+	assert.Equal(t, 1, testutil.CollectAndCount(Claims))
+	assert.Equal(t, 1.0, testutil.ToFloat64(Claims.WithLabelValues("harvestervm", "active")))
+}
@@ -0,0 +1,437 @@
+// Package notify delivers claim changes to outbound webhook subscribers.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	mrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/metrics"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// Delivery headers set on every notification request.
+const (
+	HeaderDelivery  = "X-Registry-Delivery"      // unique per notification, stable across retries
+	HeaderEvent     = "X-Registry-Event"         // always EventClaimsChanged
+	HeaderSignature = "X-Registry-Signature-256" // "sha256=" + hex HMAC of the body, when a secret is set
+)
+
+// EventClaimsChanged is the event name of change notifications.
+const EventClaimsChanged = "claims.changed"
+
+// Subscriber is a webhook endpoint and the changes it is interested in.
+// Empty filters match everything; within a filter any value may match.
+type Subscriber struct {
+	Name       string                // Identifies the subscriber in logs, metrics and dead letters
+	URL        string                // Endpoint receiving POSTed payloads
+	Secret     string                // HMAC-SHA256 signing secret; unsigned when empty
+	Events     []registry.ChangeType // Change types to deliver
+	Templates  []string              // Claim templates to deliver
+	Categories []string              // Claim categories to deliver
+	Statuses   []string              // Claim statuses to deliver (the new status of modified claims)
+	Fields     []string              // Modified claims are delivered only when one of these fields (JSON names or param.<key>) changed
+}
+
+// Matches reports whether c passes the subscriber's filters. Deleted claims
+// are matched by their last known state.
+func (s *Subscriber) Matches(c *registry.Change) bool {
+	if len(s.Events) > 0 && !slices.Contains(s.Events, c.Type) {
+		return false
+	}
+	if len(s.Templates) > 0 && !slices.Contains(s.Templates, c.Claim.Template) {
+		return false
+	}
+	if len(s.Categories) > 0 && !slices.Contains(s.Categories, c.Claim.Category) {
+		return false
+	}
+	if len(s.Statuses) > 0 && !slices.Contains(s.Statuses, c.Claim.Status) {
+		return false
+	}
+	if len(s.Fields) > 0 && c.Type == registry.Modified {
+		return slices.ContainsFunc(c.Fields, func(f registry.FieldChange) bool {
+			return slices.Contains(s.Fields, f.Field)
+		})
+	}
+	return true
+}
+
+// Payload is the JSON body POSTed to subscribers: a changeset reduced to the
+// changes matching the subscriber's filters.
+type Payload struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	ID         string `json:"id"`
+	isync.Changeset
+}
+
+// DeadLetter is a notification that could not be delivered.
+type DeadLetter struct {
+	Subscriber string    `json:"subscriber"`
+	URL        string    `json:"url"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error"`
+	Time       time.Time `json:"time"`
+	Payload    Payload   `json:"payload"`
+}
+
+// Config holds dispatcher configuration.
+type Config struct {
+	Subscribers    []Subscriber
+	MaxAttempts    int           // Delivery attempts per notification; defaults to 5
+	InitialBackoff time.Duration // Delay after the first failed attempt, doubling per attempt; defaults to 1s
+	MaxBackoff     time.Duration // Upper bound of the retry delay; defaults to 5m
+	Timeout        time.Duration // Per-attempt request timeout; defaults to 10s
+	QueueSize      int           // Pending notifications per subscriber; defaults to 100
+	DeadLetters    int           // Dead letters kept in memory; defaults to 100
+	DeadLetterFile string        // Optional file dead letters are appended to as JSON lines
+	Client         *http.Client  // Defaults to a traced client
+	Logger         *slog.Logger  // Defaults to slog.Default()
+}
+
+// Dispatcher fans changesets out to subscribers. Each subscriber has its own
+// queue and worker, so a slow or failing endpoint neither delays the others
+// nor reorders its own notifications.
+type Dispatcher struct {
+	cfg     Config
+	workers []*worker
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+	dead    []DeadLetter // newest last
+}
+
+type worker struct {
+	sub   Subscriber
+	log   *slog.Logger
+	queue chan Payload
+}
+
+// NewDispatcher validates cfg and creates a Dispatcher.
+func NewDispatcher(cfg Config) (*Dispatcher, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.DeadLetters <= 0 {
+		cfg.DeadLetters = 100
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	d := &Dispatcher{cfg: cfg}
+	names := map[string]bool{}
+	for i, sub := range cfg.Subscribers {
+		if sub.Name == "" {
+			sub.Name = "subscriber-" + strconv.Itoa(i)
+		}
+		if names[sub.Name] {
+			return nil, fmt.Errorf("subscriber %d: duplicate subscriber name %q", i, sub.Name)
+		}
+		names[sub.Name] = true
+		if u, err := url.Parse(sub.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("subscriber %s: invalid URL %q", sub.Name, sub.URL)
+		}
+		for _, t := range sub.Events {
+			if t != registry.Added && t != registry.Modified && t != registry.Deleted {
+				return nil, fmt.Errorf("subscriber %s: unknown event %q (expected ADDED, MODIFIED or DELETED)", sub.Name, t)
+			}
+		}
+		if _, err := registry.ParseFields(strings.Join(sub.Fields, ",")); err != nil {
+			return nil, fmt.Errorf("subscriber %s: %w", sub.Name, err)
+		}
+		d.workers = append(d.workers, &worker{
+			sub:   sub,
+			log:   cfg.Logger.With("subscriber", sub.Name),
+			queue: make(chan Payload, cfg.QueueSize),
+		})
+	}
+	return d, nil
+}
+
+// Start begins delivering queued notifications.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	for _, w := range d.workers {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for p := range w.queue {
+				d.deliver(ctx, w, p)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting notifications and waits for the queued ones to be
+// delivered. Once ctx is done, outstanding deliveries are abandoned and
+// dead-lettered.
+func (d *Dispatcher) Stop(ctx context.Context) {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.mu.Unlock()
+
+	if d.cancel == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+}
+
+// Notify queues the changes in cs, a changeset of the served (merged)
+// registry, for every subscriber they match. It never
+// blocks; notifications for a subscriber whose queue is full are
+// dead-lettered.
+func (d *Dispatcher) Notify(cs isync.Changeset) {
+	type rejected struct {
+		w *worker
+		p Payload
+	}
+	var full []rejected
+
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	for _, w := range d.workers {
+		var changes []registry.Change
+		for i := range cs.Changes {
+			if w.sub.Matches(&cs.Changes[i]) {
+				changes = append(changes, cs.Changes[i])
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		p := Payload{
+			APIVersion: "claim-registry.io/v1alpha1",
+			Kind:       "ChangeNotification",
+			ID:         newID(),
+			Changeset:  cs,
+		}
+		p.Changes = changes
+		select {
+		case w.queue <- p:
+		default:
+			full = append(full, rejected{w, p})
+		}
+	}
+	d.mu.Unlock()
+
+	for _, r := range full {
+		d.deadLetter(r.w, r.p, 0, errors.New("delivery queue full"))
+	}
+}
+
+// DeadLetters returns the retained dead letters, oldest first.
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.dead)
+}
+
+// deliver sends p to the worker's subscriber, retrying transient failures
+// with exponential backoff until the attempts are used up.
+func (d *Dispatcher) deliver(ctx context.Context, w *worker, p Payload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.deadLetter(w, p, 0, err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryIn, err := d.post(ctx, &w.sub, p.ID, body)
+		if err == nil {
+			metrics.NotifyDeliveries.WithLabelValues(w.sub.Name, metrics.ResultDelivered).Inc()
+			w.log.Debug("notification delivered", "id", p.ID, "attempt", attempt)
+			return
+		}
+		if retryIn < 0 || attempt >= d.cfg.MaxAttempts || ctx.Err() != nil {
+			d.deadLetter(w, p, attempt, err)
+			return
+		}
+
+		delay := max(d.backoff(attempt), retryIn)
+		w.log.Warn("notification failed", "id", p.ID, "attempt", attempt, "retryIn", delay.String(), logging.Err(err))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			d.deadLetter(w, p, attempt, err)
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt. On failure it returns how long the
+// receiver asked to wait before retrying, or -1 if retrying is pointless.
+func (d *Dispatcher) post(ctx context.Context, sub *Subscriber, id string, body []byte) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "machinery-registry-api")
+	req.Header.Set(HeaderDelivery, id)
+	req.Header.Set(HeaderEvent, EventClaimsChanged)
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, body))
+	}
+
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch code := resp.StatusCode; {
+	case code >= 200 && code < 300:
+		return 0, nil
+	case code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500:
+		var retryIn time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryIn = min(time.Duration(secs)*time.Second, d.cfg.MaxBackoff)
+		}
+		return retryIn, fmt.Errorf("unexpected status %d", code)
+	default:
+		return -1, fmt.Errorf("unexpected status %d", code)
+	}
+}
+
+// backoff returns the jittered delay after the given failed attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.MaxBackoff)
+	// Equal jitter: a random duration in [delay/2, delay].
+	if half := delay / 2; half > 0 {
+		delay = half + mrand.N(half+1)
+	}
+	return delay
+}
+
+// deadLetter records an undeliverable notification in memory, in the log
+// and, if configured, in the dead-letter file.
+func (d *Dispatcher) deadLetter(w *worker, p Payload, attempts int, err error) {
+	metrics.NotifyDeliveries.WithLabelValues(w.sub.Name, metrics.ResultFailed).Inc()
+	w.log.Error("notification dead-lettered", "id", p.ID, "attempts", attempts, logging.Err(err))
+
+	dl := DeadLetter{
+		Subscriber: w.sub.Name,
+		URL:        w.sub.URL,
+		Attempts:   attempts,
+		Error:      err.Error(),
+		Time:       time.Now().UTC(),
+		Payload:    p,
+	}
+
+	d.mu.Lock()
+	d.dead = append(d.dead, dl)
+	if len(d.dead) > d.cfg.DeadLetters {
+		d.dead = slices.Delete(d.dead, 0, len(d.dead)-d.cfg.DeadLetters)
+	}
+	d.mu.Unlock()
+
+	if d.cfg.DeadLetterFile != "" {
+		if err := appendJSONLine(d.cfg.DeadLetterFile, dl); err != nil {
+			w.log.Error("writing dead letter failed", "file", d.cfg.DeadLetterFile, logging.Err(err))
+		}
+	}
+}
+
+// appendJSONLine appends v as one line of JSON to the file at path.
+func appendJSONLine(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of body keyed with secret, as used by GitHub
+// webhooks.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newID returns a random notification ID.
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("n-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,256 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	isync "github.com/stuttgart-things/machinery-registry-api/internal/sync"
+)
+
+// delivery is a request seen by a receiver.
+type delivery struct {
+	header  http.Header
+	body    []byte
+	payload Payload
+}
+
+// receiver records deliveries and answers with the next status in codes,
+// repeating the last one.
+type receiver struct {
+	*httptest.Server
+	mu         gosync.Mutex
+	codes      []int
+	deliveries []delivery
+}
+
+func newReceiver(t *testing.T, codes ...int) *receiver {
+	t.Helper()
+	rcv := &receiver{codes: codes}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		d := delivery{header: r.Header, body: body}
+		json.Unmarshal(body, &d.payload)
+
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+		rcv.deliveries = append(rcv.deliveries, d)
+		code := http.StatusNoContent
+		if len(rcv.codes) > 0 {
+			code = rcv.codes[0]
+			if len(rcv.codes) > 1 {
+				rcv.codes = rcv.codes[1:]
+			}
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *receiver) received() []delivery {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]delivery(nil), rcv.deliveries...)
+}
+
+// startDispatcher starts a dispatcher with fast retries.
+func startDispatcher(t *testing.T, cfg Config) *Dispatcher {
+	t.Helper()
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = time.Millisecond
+	}
+	d, err := NewDispatcher(cfg)
+	require.NoError(t, err)
+	d.Start(context.Background())
+	return d
+}
+
+func testChangeset() isync.Changeset {
+	return isync.Changeset{
+		Registry:         "harvester",
+		Revision:         "rev2",
+		PreviousRevision: "rev1",
+		Time:             time.Date(2026, 2, 5, 18, 0, 0, 0, time.UTC),
+		Changes: []registry.Change{
+			{Type: registry.Added, Claim: registry.ClaimEntry{Name: "new-vm", Template: "harvestervm", Category: "infra", Status: "active"}},
+			{Type: registry.Modified, Claim: registry.ClaimEntry{Name: "hacky", Template: "volumeclaim", Category: "cli", Status: "inactive"},
+				Fields: []registry.FieldChange{{Field: "status", Old: "active", New: "inactive"}}},
+			{Type: registry.Deleted, Claim: registry.ClaimEntry{Name: "demo", Template: "volumeclaim", Category: "cli", Status: "active"}},
+		},
+	}
+}
+
+func TestSubscriberMatches(t *testing.T) {
+	cs := testChangeset()
+	added, modified, deleted := &cs.Changes[0], &cs.Changes[1], &cs.Changes[2]
+
+	tests := []struct {
+		name string
+		sub  Subscriber
+		want []bool // added, modified, deleted
+	}{
+		{"no filters", Subscriber{}, []bool{true, true, true}},
+		{"events", Subscriber{Events: []registry.ChangeType{registry.Added, registry.Deleted}}, []bool{true, false, true}},
+		{"templates", Subscriber{Templates: []string{"volumeclaim"}}, []bool{false, true, true}},
+		{"categories", Subscriber{Categories: []string{"infra"}}, []bool{true, false, false}},
+		{"statuses", Subscriber{Statuses: []string{"inactive"}}, []bool{false, true, false}},
+		{"changed fields", Subscriber{Fields: []string{"status"}}, []bool{true, true, true}},
+		{"unchanged fields", Subscriber{Fields: []string{"createdBy"}}, []bool{true, false, true}},
+		{"combined", Subscriber{Events: []registry.ChangeType{registry.Modified}, Fields: []string{"status"}, Templates: []string{"volumeclaim"}}, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []bool{tt.sub.Matches(added), tt.sub.Matches(modified), tt.sub.Matches(deleted)}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewDispatcherValidation(t *testing.T) {
+	for name, sub := range map[string]Subscriber{
+		"missing URL":   {Name: "a"},
+		"relative URL":  {Name: "a", URL: "/hooks"},
+		"unknown event": {Name: "a", URL: "http://example.com", Events: []registry.ChangeType{"CREATED"}},
+		"unknown field": {Name: "a", URL: "http://example.com", Fields: []string{"status", "owner"}},
+		"empty param":   {Name: "a", URL: "http://example.com", Fields: []string{"param."}},
+	} {
+		_, err := NewDispatcher(Config{Subscribers: []Subscriber{sub}})
+		assert.Error(t, err, name)
+	}
+
+	_, err := NewDispatcher(Config{Subscribers: []Subscriber{
+		{Name: "a", URL: "http://example.com"},
+		{Name: "a", URL: "http://example.org"},
+	}})
+	assert.ErrorContains(t, err, "duplicate subscriber name")
+
+	_, err = NewDispatcher(Config{Subscribers: []Subscriber{
+		{Name: "a", URL: "http://example.com", Fields: []string{"status", "createdBy", "param.cpu"}},
+	}})
+	assert.NoError(t, err)
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	all := newReceiver(t)
+	status := newReceiver(t)
+	none := newReceiver(t)
+
+	d := startDispatcher(t, Config{Subscribers: []Subscriber{
+		{Name: "all", URL: all.URL, Secret: "s3cret"},
+		{Name: "status", URL: status.URL, Fields: []string{"status"}, Events: []registry.ChangeType{registry.Modified}},
+		{Name: "none", URL: none.URL, Templates: []string{"vsphere-vm"}},
+	}})
+	d.Notify(testChangeset())
+	d.Stop(context.Background())
+
+	got := all.received()
+	require.Len(t, got, 1)
+	assert.Equal(t, "application/json", got[0].header.Get("Content-Type"))
+	assert.Equal(t, EventClaimsChanged, got[0].header.Get(HeaderEvent))
+	assert.Equal(t, Sign("s3cret", got[0].body), got[0].header.Get(HeaderSignature))
+	assert.Equal(t, got[0].payload.ID, got[0].header.Get(HeaderDelivery))
+	assert.Equal(t, "ChangeNotification", got[0].payload.Kind)
+	assert.Equal(t, testChangeset(), got[0].payload.Changeset)
+
+	got = status.received()
+	require.Len(t, got, 1)
+	assert.Empty(t, got[0].header.Get(HeaderSignature), "unsigned without a secret")
+	require.Len(t, got[0].payload.Changes, 1)
+	assert.Equal(t, "hacky", got[0].payload.Changes[0].Claim.Name)
+	assert.Equal(t, "rev2", got[0].payload.Revision)
+
+	assert.Empty(t, none.received(), "nothing matched")
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	rcv := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+	d := startDispatcher(t, Config{Subscribers: []Subscriber{{Name: "flaky", URL: rcv.URL}}})
+	d.Notify(testChangeset())
+	d.Stop(context.Background())
+
+	got := rcv.received()
+	require.Len(t, got, 3)
+	for _, dl := range got[1:] {
+		assert.Equal(t, got[0].header.Get(HeaderDelivery), dl.header.Get(HeaderDelivery), "retries keep the delivery ID")
+	}
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	failing := newReceiver(t, http.StatusInternalServerError)
+	rejecting := newReceiver(t, http.StatusBadRequest)
+	file := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+
+	d := startDispatcher(t, Config{
+		Subscribers: []Subscriber{
+			{Name: "failing", URL: failing.URL},
+			{Name: "rejecting", URL: rejecting.URL},
+		},
+		MaxAttempts:    3,
+		DeadLetterFile: file,
+	})
+	d.Notify(testChangeset())
+	d.Stop(context.Background())
+
+	assert.Len(t, failing.received(), 3, "server errors are retried")
+	assert.Len(t, rejecting.received(), 1, "client errors are not retried")
+
+	dead := d.DeadLetters()
+	require.Len(t, dead, 2)
+	attempts := map[string]int{}
+	for _, dl := range dead {
+		attempts[dl.Subscriber] = dl.Attempts
+		assert.Contains(t, dl.Error, "unexpected status")
+		assert.Len(t, dl.Payload.Changes, 3)
+	}
+	assert.Equal(t, map[string]int{"failing": 3, "rejecting": 1}, attempts)
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	var lines int
+	for sc := bufio.NewScanner(f); sc.Scan(); lines++ {
+		var dl DeadLetter
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &dl))
+		assert.NotEmpty(t, dl.Payload.ID)
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestDispatcherStopAbandonsRetries(t *testing.T) {
+	rcv := newReceiver(t, http.StatusServiceUnavailable)
+
+	d := startDispatcher(t, Config{
+		Subscribers:    []Subscriber{{Name: "down", URL: rcv.URL}},
+		InitialBackoff: time.Hour,
+	})
+	d.Notify(testChangeset())
+	require.Eventually(t, func() bool { return len(rcv.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	d.Stop(ctx)
+
+	dead := d.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, 1, dead[0].Attempts)
+
+	// Notifications after Stop are ignored.
+	d.Notify(testChangeset())
+	assert.Len(t, d.DeadLetters(), 1)
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"reflect"
+	"slices"
+)
+
+// ChangeType classifies how a claim differs between two registry versions.
+type ChangeType string
+
+const (
+	Added    ChangeType = "ADDED"
+	Modified ChangeType = "MODIFIED"
+	Deleted  ChangeType = "DELETED"
+)
+
+// Change is a single claim added, modified or deleted between two registry
+// versions. Deleted changes carry the last known state of the claim;
+// modifications list the fields that differ.
+type Change struct {
+	Type   ChangeType    `json:"type"`
+	Claim  ClaimEntry    `json:"claim"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is the old and new value of a modified claim field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// diffFields lists the compared fields in a stable order.
+var diffFields = slices.Sorted(slices.Values(textFields))
+
+// FieldChanges returns the fields whose values differ between a and b,
+// ordered by field name, followed by the changed parameters.
+func FieldChanges(a, b *ClaimEntry) []FieldChange {
+	var changes []FieldChange
+	for _, f := range diffFields {
+		av, _ := a.Field(f)
+		bv, _ := b.Field(f)
+		if av != bv {
+			changes = append(changes, FieldChange{Field: f, Old: av, New: bv})
+		}
+	}
+	return append(changes, paramChanges(a, b)...)
+}
+
+// Diff returns the changes turning prev into next, matching entries by name.
+// Additions and modifications come in next's order, followed by deletions
+// in prev's order.
+func Diff(prev, next []ClaimEntry) []Change {
+	before := make(map[string]*ClaimEntry, len(prev))
+	for i := range prev {
+		if _, dup := before[prev[i].Name]; !dup {
+			before[prev[i].Name] = &prev[i]
+		}
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(next))
+	for i := range next {
+		e := &next[i]
+		if seen[e.Name] {
+			continue
+		}
+		seen[e.Name] = true
+
+		old, ok := before[e.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: Added, Claim: *e})
+		case !reflect.DeepEqual(*old, *e):
+			changes = append(changes, Change{Type: Modified, Claim: *e, Fields: FieldChanges(old, e)})
+		}
+	}
+	for i := range prev {
+		if e := &prev[i]; !seen[e.Name] {
+			seen[e.Name] = true
+			changes = append(changes, Change{Type: Deleted, Claim: *e})
+		}
+	}
+	return changes
+}
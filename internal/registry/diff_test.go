@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	prev := []ClaimEntry{
+		{Name: "kept", Template: "volumeclaim"},
+		{Name: "changed", Status: "active"},
+		{Name: "removed"},
+	}
+	next := []ClaimEntry{
+		{Name: "added"},
+		{Name: "changed", Status: "inactive"},
+		{Name: "kept", Template: "volumeclaim"},
+	}
+
+	assert.Equal(t, []Change{
+		{Type: Added, Claim: ClaimEntry{Name: "added"}},
+		{
+			Type:   Modified,
+			Claim:  ClaimEntry{Name: "changed", Status: "inactive"},
+			Fields: []FieldChange{{Field: "status", Old: "active", New: "inactive"}},
+		},
+		{Type: Deleted, Claim: ClaimEntry{Name: "removed"}},
+	}, Diff(prev, next))
+}
+
+func TestDiffEdges(t *testing.T) {
+	entries := []ClaimEntry{{Name: "a"}, {Name: "b"}}
+
+	assert.Empty(t, Diff(entries, entries))
+	assert.Equal(t, []Change{{Type: Added, Claim: ClaimEntry{Name: "a"}}, {Type: Added, Claim: ClaimEntry{Name: "b"}}}, Diff(nil, entries))
+	assert.Equal(t, []Change{{Type: Deleted, Claim: ClaimEntry{Name: "a"}}, {Type: Deleted, Claim: ClaimEntry{Name: "b"}}}, Diff(entries, nil))
+}
+
+func TestFieldChanges(t *testing.T) {
+	a := ClaimEntry{Name: "x", Template: "volumeclaim", Status: "active", Registry: "one"}
+	b := ClaimEntry{Name: "x", Template: "harvestervm", Status: "active", Registry: "two"}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "registry", Old: "one", New: "two"},
+		{Field: "template", Old: "volumeclaim", New: "harvestervm"},
+	}, FieldChanges(&a, &b))
+	assert.Empty(t, FieldChanges(&a, &a))
+}
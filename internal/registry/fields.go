@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// claimField is a ClaimEntry field as encoded to JSON.
+type claimField struct {
+	name      string // JSON name, e.g. "createdAt"
+	index     int    // Index in ClaimEntry
+	omitEmpty bool   // Tagged omitempty
+
+	// get reads a string field, usable in sorts, selectors, CSV and diffs;
+	// nil for other fields.
+	get func(*ClaimEntry) string
+}
+
+// claimFields lists the JSON-encoded ClaimEntry fields in declaration order.
+// It is derived from the struct tags, so a new field can be sorted on,
+// selected, exported as CSV, diffed and projected without further changes.
+var claimFields = func() []claimField {
+	var fields []claimField
+	typ := reflect.TypeFor[ClaimEntry]()
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		cf := claimField{
+			name:      name,
+			index:     i,
+			omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+		}
+		if f.Type.Kind() == reflect.String {
+			cf.get = stringAt(f.Offset)
+		}
+		fields = append(fields, cf)
+	}
+	return fields
+}()
+
+// stringAt returns an accessor for the string field at offset in
+// ClaimEntry. Filters and sorts read fields of every entry, which is too hot
+// a path for reflection.
+func stringAt(offset uintptr) func(*ClaimEntry) string {
+	return func(e *ClaimEntry) string {
+		return *(*string)(unsafe.Add(unsafe.Pointer(e), offset))
+	}
+}
+
+// fieldsByName indexes claimFields by JSON name.
+var fieldsByName = func() map[string]claimField {
+	m := make(map[string]claimField, len(claimFields))
+	for _, f := range claimFields {
+		m[f.name] = f
+	}
+	return m
+}()
+
+// fieldGetters maps the JSON names of the string fields to their
+// accessors.
+var fieldGetters = func() map[string]func(*ClaimEntry) string {
+	m := make(map[string]func(*ClaimEntry) string, len(claimFields))
+	for _, f := range claimFields {
+		if f.get != nil {
+			m[f.name] = f.get
+		}
+	}
+	return m
+}()
+
+// textFields lists the JSON names of the string fields in declaration
+// order.
+var textFields = func() []string {
+	var names []string
+	for _, f := range claimFields {
+		if f.get != nil {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}()
+
+// FieldNames returns the JSON names of the string ClaimEntry fields in
+// declaration order.
+func FieldNames() []string {
+	return slices.Clone(textFields)
+}
+
+// isField reports whether name is a string claim field or a parameter
+// reference.
+func isField(name string) bool {
+	if key, ok := strings.CutPrefix(name, ParamPrefix); ok {
+		return key != ""
+	}
+	_, ok := fieldGetters[name]
+	return ok
+}
+
+// Field returns the value of the field with the given JSON name, e.g.
+// "createdAt", or of a parameter, e.g. "param.cpu". The second result is
+// false for unknown and non-string fields; unset parameters are empty.
+func (e *ClaimEntry) Field(name string) (string, bool) {
+	if key, ok := strings.CutPrefix(name, ParamPrefix); ok && key != "" {
+		v, _ := e.Param(key)
+		return v, true
+	}
+	get, ok := fieldGetters[name]
+	if !ok {
+		return "", false
+	}
+	return get(e), true
+}
+
+// fieldGetter returns the accessor of the field or parameter that Field
+// reads for name. Loops over many entries resolve it once up front.
+func fieldGetter(name string) func(*ClaimEntry) string {
+	if key, ok := strings.CutPrefix(name, ParamPrefix); ok && key != "" {
+		return func(e *ClaimEntry) string {
+			v, _ := e.Param(key)
+			return v
+		}
+	}
+	if get, ok := fieldGetters[name]; ok {
+		return get
+	}
+	return func(*ClaimEntry) string { return "" }
+}
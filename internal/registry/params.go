@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParamPrefix prefixes field names that address claim parameters, e.g.
+// "param.cpu" or "param.disk.size" for nested values.
+const ParamPrefix = "param."
+
+// Param returns the parameter at the dot-separated path key rendered as a
+// string: scalars in their YAML form, lists and maps as JSON. The second
+// result is false when the parameter is not set.
+func (e *ClaimEntry) Param(key string) (string, bool) {
+	var v any = e.Parameters
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if v, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+	return formatParam(v), true
+}
+
+// formatParam renders a parameter value for comparison and display.
+func formatParam(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, int64, uint64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// flattenParams returns every scalar, list or empty map parameter keyed by
+// its ParamPrefix field name.
+func flattenParams(params map[string]any) map[string]string {
+	flat := map[string]string{}
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			flat[prefix+k] = formatParam(v)
+		}
+	}
+	walk(ParamPrefix, params)
+	return flat
+}
+
+// paramChanges returns the parameters whose values differ between a and b,
+// ordered by field name.
+func paramChanges(a, b *ClaimEntry) []FieldChange {
+	if len(a.Parameters) == 0 && len(b.Parameters) == 0 {
+		return nil
+	}
+	old, cur := flattenParams(a.Parameters), flattenParams(b.Parameters)
+	keys := slices.Sorted(maps.Keys(old))
+	for k := range cur {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []FieldChange
+	for _, k := range keys {
+		if old[k] != cur[k] {
+			changes = append(changes, FieldChange{Field: k, Old: old[k], New: cur[k]})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimEntryParam(t *testing.T) {
+	e := ClaimEntry{Name: "vm", Parameters: map[string]any{
+		"cpu":     4,
+		"memory":  "8Gi",
+		"ratio":   0.5,
+		"enabled": true,
+		"disk":    map[string]any{"size": "20Gi"},
+		"tags":    []any{"a", "b"},
+	}}
+
+	for field, want := range map[string]string{
+		"param.cpu":       "4",
+		"param.memory":    "8Gi",
+		"param.ratio":     "0.5",
+		"param.enabled":   "true",
+		"param.disk.size": "20Gi",
+		"param.disk":      `{"size":"20Gi"}`,
+		"param.tags":      `["a","b"]`,
+	} {
+		got, ok := e.Field(field)
+		assert.True(t, ok, field)
+		assert.Equal(t, want, got, field)
+	}
+
+	// Unset parameters are known fields with an empty value.
+	for _, field := range []string{"param.gpu", "param.cpu.cores", "param.disk.type"} {
+		got, ok := e.Field(field)
+		assert.True(t, ok, field)
+		assert.Empty(t, got, field)
+	}
+	_, ok := e.Field("param.")
+	assert.False(t, ok)
+}
+
+func TestSelectParams(t *testing.T) {
+	entries := []ClaimEntry{
+		{Name: "small", Parameters: map[string]any{"cpu": 2, "disk": map[string]any{"size": "10Gi"}}},
+		{Name: "large", Parameters: map[string]any{"cpu": 4, "disk": map[string]any{"size": "50Gi"}}},
+		{Name: "none"},
+	}
+
+	sel, err := ParseSelector("param.cpu=4")
+	require.NoError(t, err)
+	assert.Equal(t, []ClaimEntry{entries[1]}, sel.Filter(entries))
+
+	sel, err = ParseSelector("param.disk.size in (10Gi,50Gi),param.cpu!=4")
+	require.NoError(t, err)
+	assert.Equal(t, []ClaimEntry{entries[0]}, sel.Filter(entries))
+
+	sel, err = ParseSelector("!param.cpu")
+	require.NoError(t, err)
+	assert.Equal(t, []ClaimEntry{entries[2]}, sel.Filter(entries))
+
+	keys, err := ParseSort("-param.cpu")
+	require.NoError(t, err)
+	SortEntries(entries, keys)
+	assert.Equal(t, "large", entries[0].Name)
+}
+
+func TestFieldChangesParams(t *testing.T) {
+	a := ClaimEntry{Name: "vm", Parameters: map[string]any{"cpu": 2, "disk": map[string]any{"size": "10Gi"}}}
+	b := ClaimEntry{Name: "vm", Parameters: map[string]any{"cpu": 4, "gpu": true, "disk": map[string]any{"size": "10Gi"}}}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "param.cpu", Old: "2", New: "4"},
+		{Field: "param.gpu", Old: "", New: "true"},
+	}, FieldChanges(&a, &b))
+	assert.Empty(t, FieldChanges(&a, &a))
+}
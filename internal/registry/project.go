@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Projection selects the ClaimEntry fields included in a response. A nil
+// Projection selects every field.
+type Projection struct {
+	fields []claimField // selected fields in declaration order
+	params []string     // selected parameter keys; nil selects all parameters
+}
+
+// ParseProjection parses a comma-separated list of JSON field names such as
+// "name,template,status"; "param.<key>" selects a single parameter. It
+// returns nil for an empty list.
+func ParseProjection(spec string) (*Projection, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	selected := map[string]bool{}
+	var params []string
+	allParams := false
+	for part := range strings.SplitSeq(spec, ",") {
+		name := strings.TrimSpace(part)
+		allParams = allParams || name == paramsField.name
+		if key, ok := strings.CutPrefix(name, ParamPrefix); ok && key != "" {
+			if !slices.Contains(params, key) {
+				params = append(params, key)
+			}
+			selected[paramsField.name] = true
+			continue
+		}
+		if _, ok := fieldsByName[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		selected[name] = true
+	}
+	p := &Projection{}
+	if !allParams {
+		p.params = params
+	}
+	for _, f := range claimFields {
+		if selected[f.name] {
+			p.fields = append(p.fields, f)
+		}
+	}
+	return p, nil
+}
+
+// paramsField is the field holding claim parameters.
+var paramsField = fieldsByName["parameters"]
+
+// Includes reports whether the projection selects the field with the given
+// JSON name.
+func (p *Projection) Includes(name string) bool {
+	if p == nil {
+		_, ok := fieldsByName[name]
+		return ok
+	}
+	return slices.ContainsFunc(p.fields, func(f claimField) bool { return f.name == name })
+}
+
+// View returns e reduced to the projected fields.
+func (p *Projection) View(e *ClaimEntry) ClaimView {
+	return ClaimView{entry: e, projection: p}
+}
+
+// ClaimView is a ClaimEntry reduced to the fields of a Projection. It
+// encodes to a JSON object holding only those fields, in declaration order,
+// followed by the fields of Extra.
+type ClaimView struct {
+	entry      *ClaimEntry
+	projection *Projection
+	Extra      any // Struct encoded into the same object, e.g. expanded data
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v ClaimView) MarshalJSON() ([]byte, error) {
+	fields := claimFields
+	if v.projection != nil {
+		fields = v.projection.fields
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	value := reflect.ValueOf(v.entry).Elem()
+	for _, f := range fields {
+		fv := value.Field(f.index)
+		if f.index == paramsField.index && v.projection != nil && v.projection.params != nil {
+			fv = reflect.ValueOf(selectParams(v.entry.Parameters, v.projection.params))
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		data, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("encoding field %s: %w", f.name, err)
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(data)
+	}
+
+	if v.Extra != nil {
+		data, err := json.Marshal(v.Extra)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 2 || data[0] != '{' {
+			return nil, fmt.Errorf("extra fields encode to %s, not an object", data)
+		}
+		if inner := bytes.TrimSpace(data[1 : len(data)-1]); len(inner) > 0 {
+			if b.Len() > 1 {
+				b.WriteByte(',')
+			}
+			b.Write(inner)
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// isEmptyValue reports whether v is empty in the sense of omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// selectParams returns the parameters at the dot-separated keys, nested as
+// in params. Keys that are not set, or are inside another selected key, are
+// left out.
+func selectParams(params map[string]any, keys []string) map[string]any {
+	out := map[string]any{}
+	var taken []string
+	for _, key := range slices.Sorted(slices.Values(keys)) {
+		if slices.ContainsFunc(taken, func(t string) bool { return strings.HasPrefix(key, t+".") }) {
+			continue
+		}
+		taken = append(taken, key)
+		path := strings.Split(key, ".")
+		var v any = params
+		for _, part := range path {
+			m, ok := v.(map[string]any)
+			if !ok {
+				v = nil
+				break
+			}
+			if v, ok = m[part]; !ok {
+				break
+			}
+		}
+		if v == nil {
+			continue
+		}
+		dst := out
+		for _, part := range path[:len(path)-1] {
+			next, ok := dst[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				dst[part] = next
+			}
+			dst = next
+		}
+		dst[path[len(path)-1]] = v
+	}
+	return out
+}
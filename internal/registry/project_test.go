@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProjection(t *testing.T) {
+	p, err := ParseProjection("status, name,template,name")
+	require.NoError(t, err)
+	assert.True(t, p.Includes("name"))
+	assert.False(t, p.Includes("category"))
+
+	p, err = ParseProjection("")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	assert.True(t, p.Includes("category"), "nil selects every field")
+
+	_, err = ParseProjection("name,owner")
+	assert.EqualError(t, err, `unknown field "owner"`)
+	_, err = ParseProjection("param.")
+	assert.EqualError(t, err, `unknown field "param."`)
+}
+
+func TestProjectParams(t *testing.T) {
+	e := ClaimEntry{Name: "vm", Parameters: map[string]any{
+		"cpu":  4,
+		"disk": map[string]any{"size": "20Gi", "class": "longhorn"},
+		"tags": []any{"a"},
+	}}
+	encode := func(spec string, e *ClaimEntry) string {
+		p, err := ParseProjection(spec)
+		require.NoError(t, err, spec)
+		data, err := json.Marshal(p.View(e))
+		require.NoError(t, err, spec)
+		return string(data)
+	}
+
+	assert.Equal(t, `{"name":"vm","parameters":{"cpu":4,"disk":{"size":"20Gi"}}}`, encode("name,param.disk.size,param.cpu,param.missing", &e))
+	assert.Equal(t, `{"parameters":{"disk":{"class":"longhorn","size":"20Gi"}}}`, encode("param.disk.size,param.disk", &e), "a selected map includes its keys")
+	assert.Equal(t, `{"parameters":{"cpu":4,"disk":{"class":"longhorn","size":"20Gi"},"tags":["a"]}}`, encode("param.cpu,parameters", &e), "parameters selects all")
+	assert.Equal(t, `{"name":"bare"}`, encode("name,param.cpu", &ClaimEntry{Name: "bare"}))
+	assert.Equal(t, map[string]any{"size": "20Gi", "class": "longhorn"}, e.Parameters["disk"], "the entry is not modified")
+}
+
+func TestClaimView(t *testing.T) {
+	e := ClaimEntry{Name: "vm", Template: "harvestervm", Status: "active", Parameters: map[string]any{"cpu": 4}}
+
+	p, err := ParseProjection("status,name,registry,parameters")
+	require.NoError(t, err)
+	data, err := json.Marshal(p.View(&e))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"vm","status":"active","parameters":{"cpu":4}}`, string(data), "declaration order, omitempty honoured")
+
+	view := p.View(&ClaimEntry{Name: "bare"})
+	view.Extra = struct {
+		Manifest string `json:"manifest,omitempty"`
+	}{Manifest: "m"}
+	data, err = json.Marshal(view)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"bare","status":"","manifest":"m"}`, string(data))
+
+	// Without a projection a view encodes like the entry itself.
+	want, err := json.Marshal(e)
+	require.NoError(t, err)
+	data, err = json.Marshal((*Projection)(nil).View(&e))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(data))
+}
+
+func TestProjectionCoversClaimEntry(t *testing.T) {
+	typ := reflect.TypeOf(ClaimEntry{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		p, err := ParseProjection(name)
+		require.NoError(t, err, name)
+		assert.True(t, p.Includes(name), name)
+	}
+}
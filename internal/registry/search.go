@@ -0,0 +1,366 @@
+package registry
+
+import (
+	"html"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// searchFields lists the fields covered by full-text search and their
+// ranking weights.
+var searchFields = []struct {
+	name   string
+	weight float64
+}{
+	{"name", 3},
+	{"template", 2},
+	{"createdBy", 1.5},
+	{"repository", 1},
+	{"path", 1},
+}
+
+// Match qualities by kind; an exact token match scores highest.
+const (
+	qualityExact     = 1.0
+	qualityPrefix    = 0.75
+	qualityFuzzy     = 0.5
+	qualitySubstring = 0.4
+)
+
+// Hit is a ranked search result. Highlights maps each matching field to its
+// HTML-escaped value with matched text wrapped in <em> tags.
+type Hit struct {
+	Score      float64           `json:"score"`
+	Claim      ClaimEntry        `json:"claim"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// span is a byte range within a field value.
+type span struct{ start, end int }
+
+// term is a lowercased token and its location in the original text.
+type term struct {
+	text string
+	span span
+}
+
+// posting records one occurrence of a term.
+type posting struct {
+	doc   int
+	field int // index into searchFields
+	span  span
+}
+
+// Index is an in-memory inverted index over claim entries. It is immutable
+// once built and safe for concurrent use.
+type Index struct {
+	entries  []ClaimEntry
+	postings map[string][]posting
+	terms    []string           // sorted vocabulary
+	grams    map[string][]int32 // bigram -> positions in terms, ascending
+}
+
+// NewIndex builds a search index over entries.
+func NewIndex(entries []ClaimEntry) *Index {
+	ix := &Index{entries: entries, postings: map[string][]posting{}}
+	for doc, e := range entries {
+		for field, f := range searchFields {
+			value, _ := e.Field(f.name)
+			for _, t := range tokenize(value) {
+				ix.postings[t.text] = append(ix.postings[t.text], posting{doc: doc, field: field, span: t.span})
+			}
+		}
+	}
+	for t := range ix.postings {
+		ix.terms = append(ix.terms, t)
+	}
+	slices.Sort(ix.terms)
+
+	ix.grams = map[string][]int32{}
+	for i, t := range ix.terms {
+		for _, g := range bigrams(t, true) {
+			ix.grams[g] = append(ix.grams[g], int32(i))
+		}
+	}
+	return ix
+}
+
+// bigrams returns the distinct byte bigrams of t. With padded set, t is
+// enclosed in "^" and "$" first, so that its ends form bigrams of their own.
+func bigrams(t string, padded bool) []string {
+	if padded {
+		t = "^" + t + "$"
+	}
+	var grams []string
+	for i := 0; i+2 <= len(t); i++ {
+		if g := t[i : i+2]; !slices.Contains(grams, g) {
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
+
+// tokenize splits s into lowercase alphanumeric terms, so that
+// "harvestervm-developer-martin" yields harvestervm, developer and martin.
+func tokenize(s string) []term {
+	var (
+		terms []term
+		start = -1
+	)
+	flush := func(end int) {
+		if start >= 0 {
+			terms = append(terms, term{text: strings.ToLower(s[start:end]), span: span{start, end}})
+			start = -1
+		}
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(s))
+	return terms
+}
+
+// termMatch is an indexed term matched by a query term.
+type termMatch struct {
+	term    string
+	quality float64
+	offset  int // where the query term starts within the indexed term
+	length  int // matched length; 0 highlights the whole term
+}
+
+// maxEdits returns the edit distance tolerated for a query term.
+func maxEdits(q string) int {
+	switch n := len(q); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// lookup returns the indexed terms matching a query term: exact, prefix,
+// substring (three characters or more) and fuzzy matches within maxEdits.
+// Exact and prefix matches are a range of the sorted vocabulary; substring
+// and fuzzy candidates are the terms sharing enough bigrams with q.
+func (ix *Index) lookup(q string) []termMatch {
+	found := map[int32]termMatch{}
+
+	start, _ := slices.BinarySearch(ix.terms, q)
+	for i := start; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], q); i++ {
+		if t := ix.terms[i]; t == q {
+			found[int32(i)] = termMatch{term: t, quality: qualityExact}
+		} else {
+			found[int32(i)] = termMatch{term: t, quality: qualityPrefix, length: len(q)}
+		}
+	}
+	covered := func(i int32) bool {
+		_, ok := found[i]
+		return ok
+	}
+
+	// Every bigram of a substring occurs in the term.
+	if len(q) >= 3 {
+		grams := bigrams(q, false)
+		for _, i := range ix.candidates(grams, len(grams)) {
+			if t := ix.terms[i]; !covered(i) && strings.Contains(t, q) {
+				found[i] = termMatch{term: t, quality: qualitySubstring, offset: strings.Index(t, q), length: len(q)}
+			}
+		}
+	}
+
+	// An edit destroys at most three of the padded bigrams of q (a
+	// transposition), so a term within edits shares all but 3*edits of them,
+	// and at least one for the query lengths maxEdits allows.
+	if edits := maxEdits(q); edits > 0 {
+		grams := bigrams(q, true)
+		for _, i := range ix.candidates(grams, max(1, len(grams)-3*edits)) {
+			t := ix.terms[i]
+			if covered(i) || len(t) > len(q)+edits || len(t) < len(q)-edits {
+				continue
+			}
+			if d := editDistance(q, t, edits); d <= edits {
+				found[i] = termMatch{term: t, quality: qualityFuzzy / float64(d)}
+			}
+		}
+	}
+
+	var matches []termMatch
+	for _, i := range slices.Sorted(maps.Keys(found)) {
+		matches = append(matches, found[i])
+	}
+	return matches
+}
+
+// candidates returns the positions of the terms containing at least need
+// of the distinct bigrams grams.
+func (ix *Index) candidates(grams []string, need int) []int32 {
+	var (
+		result []int32
+		counts = make([]int32, len(ix.terms))
+	)
+	for _, g := range grams {
+		for _, i := range ix.grams[g] {
+			if counts[i]++; int(counts[i]) == need {
+				result = append(result, i)
+			}
+		}
+	}
+	return result
+}
+
+// Search returns up to limit entries matching query, best first. Every query
+// term contributes the best match quality per field, weighted by field;
+// entries matching only some terms are scaled down proportionally. A limit
+// of zero returns all hits.
+func (ix *Index) Search(query string, limit int) []Hit {
+	var qterms []string
+	for _, t := range tokenize(query) {
+		if !slices.Contains(qterms, t.text) {
+			qterms = append(qterms, t.text)
+		}
+	}
+	if len(qterms) == 0 {
+		return nil
+	}
+
+	type result struct {
+		score   float64
+		matched int
+		spans   map[int][]span // by field
+	}
+	results := map[int]*result{}
+
+	for _, q := range qterms {
+		type key struct{ doc, field int }
+		best := map[key]float64{}
+		for _, m := range ix.lookup(q) {
+			for _, p := range ix.postings[m.term] {
+				k := key{p.doc, p.field}
+				best[k] = math.Max(best[k], m.quality)
+
+				r := results[p.doc]
+				if r == nil {
+					r = &result{spans: map[int][]span{}}
+					results[p.doc] = r
+				}
+				s := p.span
+				if m.length > 0 {
+					s = span{min(s.start+m.offset, s.end), min(s.start+m.offset+m.length, s.end)}
+				}
+				r.spans[p.field] = append(r.spans[p.field], s)
+			}
+		}
+
+		seen := map[int]bool{}
+		for k, quality := range best {
+			results[k.doc].score += quality * searchFields[k.field].weight
+			if !seen[k.doc] {
+				seen[k.doc] = true
+				results[k.doc].matched++
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(results))
+	for doc, r := range results {
+		e := ix.entries[doc]
+		h := Hit{
+			Score:      math.Round(r.score*float64(r.matched)/float64(len(qterms))*1000) / 1000,
+			Claim:      e,
+			Highlights: make(map[string]string, len(r.spans)),
+		}
+		for field, spans := range r.spans {
+			name := searchFields[field].name
+			value, _ := e.Field(name)
+			h.Highlights[name] = highlight(value, spans)
+		}
+		hits = append(hits, h)
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Claim.Name, b.Claim.Name)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// highlight HTML-escapes value and wraps the (possibly overlapping) spans
+// in <em> tags.
+func highlight(value string, spans []span) string {
+	slices.SortFunc(spans, func(a, b span) int { return a.start - b.start })
+
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(spans); i++ {
+		s := spans[i]
+		for i+1 < len(spans) && spans[i+1].start <= s.end {
+			i++
+			s.end = max(s.end, spans[i].end)
+		}
+		if s.start < pos {
+			s.start = pos
+		}
+		b.WriteString(html.EscapeString(value[pos:s.start]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(value[s.start:s.end]))
+		b.WriteString("</em>")
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(value[pos:]))
+	return b.String()
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b (Levenshtein plus adjacent transpositions, so "matrin" is one edit from
+// "martin"), or limit+1 as soon as it is known to exceed limit.
+func editDistance(a, b string, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := tokenize("claims/cli/Harvestervm-developer-martin.yaml")
+
+	var texts []string
+	for _, tm := range terms {
+		texts = append(texts, tm.text)
+	}
+	assert.Equal(t, []string{"claims", "cli", "harvestervm", "developer", "martin", "yaml"}, texts)
+	assert.Equal(t, span{11, 22}, terms[2].span)
+}
+
+func TestIndexSearch(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+	ix := NewIndex(reg.Claims)
+
+	hits := ix.Search("martin", 0)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "harvestervm-developer-martin", hits[0].Claim.Name)
+	assert.Equal(t, "harvestervm-developer-<em>martin</em>", hits[0].Highlights["name"])
+	assert.Equal(t, "claims/cli/harvestervm-developer-<em>martin</em>.yaml", hits[0].Highlights["path"])
+
+	// A name match outranks a createdBy-only match
+	hits = ix.Search("patrick hacky", 0)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "hacky", hits[0].Claim.Name)
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	assert.Empty(t, ix.Search("nothing-like-this", 0))
+	assert.Empty(t, ix.Search("  ", 0))
+	assert.Len(t, ix.Search("claims", 2), 2)
+}
+
+func TestIndexSearchPrefixAndSubstring(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+	ix := NewIndex(reg.Claims)
+
+	hits := ix.Search("harv", 0)
+	require.NotEmpty(t, hits)
+	assert.Equal(t, "harvestervm-developer-martin", hits[0].Claim.Name)
+	assert.Equal(t, "<em>harv</em>estervm-developer-martin", hits[0].Highlights["name"])
+
+	hits = ix.Search("project", 0)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "demo-<em>project</em>", hits[0].Highlights["name"])
+	assert.Equal(t, "harbor<em>project</em>", hits[0].Highlights["template"])
+}
+
+func TestIndexSearchFuzzy(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+	ix := NewIndex(reg.Claims)
+
+	hits := ix.Search("matrin", 0)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "harvestervm-developer-martin", hits[0].Claim.Name)
+
+	exact := ix.Search("martin", 0)
+	assert.Greater(t, exact[0].Score, hits[0].Score)
+}
+
+func TestIndexLookupMatchesScan(t *testing.T) {
+	ix := NewIndex([]ClaimEntry{
+		{Name: "abcd-acbd-bacd-abdc-abc-abcde-xabcd"},
+		{Name: "martin-matrin-marti-mrtn-artin-martina-smartin"},
+		{Name: "harvestervm-harvester-harvestrvm-vmharvester"},
+	})
+
+	// scan is the linear lookup over the whole vocabulary.
+	scan := func(q string) []termMatch {
+		var matches []termMatch
+		edits := maxEdits(q)
+		for _, tm := range ix.terms {
+			switch {
+			case tm == q:
+				matches = append(matches, termMatch{term: tm, quality: qualityExact})
+			case strings.HasPrefix(tm, q):
+				matches = append(matches, termMatch{term: tm, quality: qualityPrefix, length: len(q)})
+			case len(q) >= 3 && strings.Contains(tm, q):
+				matches = append(matches, termMatch{term: tm, quality: qualitySubstring, offset: strings.Index(tm, q), length: len(q)})
+			case edits > 0:
+				if d := editDistance(q, tm, edits); d <= edits {
+					matches = append(matches, termMatch{term: tm, quality: qualityFuzzy / float64(d)})
+				}
+			}
+		}
+		return matches
+	}
+
+	for _, q := range []string{"abcd", "acbd", "ab", "bcd", "martin", "mrtain", "atri", "harvestervm", "hravestrevm", "vm", "zzzz"} {
+		assert.Equal(t, scan(q), ix.lookup(q), q)
+	}
+}
+
+func TestSnapshotBuildsSearchIndexOnFirstSearch(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+	snap := NewSnapshot(reg, "")
+	assert.Nil(t, snap.search)
+
+	assert.NotEmpty(t, snap.Search("martin", 0))
+	assert.NotNil(t, snap.search)
+}
+
+func TestHighlightEscapes(t *testing.T) {
+	assert.Equal(t, "a&lt;<em>b</em>&gt;c", highlight("a<b>c", []span{{2, 3}}))
+	assert.Equal(t, "<em>abcd</em>e", highlight("abcde", []span{{2, 4}, {0, 3}}))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("martin", "martin", 2))
+	assert.Equal(t, 1, editDistance("matrin", "martin", 2))
+	assert.Equal(t, 1, editDistance("marti", "martin", 2))
+	assert.Equal(t, 2, editDistance("mrtn", "martin", 2))
+	assert.Equal(t, 2, editDistance("abc", "abcdefgh", 1))
+}
@@ -0,0 +1,324 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Operator is the comparison applied by a selector requirement.
+type Operator string
+
+const (
+	OpEquals       Operator = "="
+	OpNotEquals    Operator = "!="
+	OpIn           Operator = "in"
+	OpNotIn        Operator = "notin"
+	OpExists       Operator = "exists"
+	OpDoesNotExist Operator = "!"
+)
+
+// Requirement is a single selector term such as "namespace!=default" or
+// "template in (harvestervm,volumeclaim)". Exists and DoesNotExist test
+// whether the field is set (non-empty).
+type Requirement struct {
+	Field    string
+	Operator Operator
+	Values   []string
+}
+
+// Matches reports whether the entry satisfies the requirement.
+func (r Requirement) Matches(e *ClaimEntry) bool {
+	v, _ := e.Field(r.Field)
+	return r.matchesValue(v)
+}
+
+// matchesValue reports whether the field value v satisfies the requirement.
+func (r Requirement) matchesValue(v string) bool {
+	switch r.Operator {
+	case OpEquals:
+		return v == r.Values[0]
+	case OpNotEquals:
+		return v != r.Values[0]
+	case OpIn:
+		return slices.Contains(r.Values, v)
+	case OpNotIn:
+		return !slices.Contains(r.Values, v)
+	case OpExists:
+		return v != ""
+	case OpDoesNotExist:
+		return v == ""
+	}
+	return false
+}
+
+// String renders the requirement in selector syntax.
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OpExists:
+		return r.Field
+	case OpDoesNotExist:
+		return "!" + r.Field
+	case OpIn, OpNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Field, r.Operator, strings.Join(r.Values, ","))
+	default:
+		return r.Field + string(r.Operator) + r.Values[0]
+	}
+}
+
+// Selector is a conjunction of requirements. The empty selector matches
+// every entry.
+type Selector []Requirement
+
+// Matches reports whether the entry satisfies all requirements.
+func (s Selector) Matches(e *ClaimEntry) bool {
+	for _, r := range s {
+		if !r.Matches(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the entries matching the selector.
+func (s Selector) Filter(entries []ClaimEntry) []ClaimEntry {
+	matches := s.matcher()
+	var result []ClaimEntry
+	for i := range entries {
+		if matches(&entries[i]) {
+			result = append(result, entries[i])
+		}
+	}
+	return result
+}
+
+// matcher returns Matches with the field accessors resolved once, for
+// matching many entries.
+func (s Selector) matcher() func(*ClaimEntry) bool {
+	gets := make([]func(*ClaimEntry) string, len(s))
+	for i, r := range s {
+		gets[i] = fieldGetter(r.Field)
+	}
+	return func(e *ClaimEntry) bool {
+		for i, r := range s {
+			if !r.matchesValue(gets[i](e)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// String renders the selector in the syntax accepted by ParseSelector.
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// SelectorError describes why a selector could not be parsed.
+type SelectorError struct {
+	Input   string
+	Offset  int // Byte offset of the offending token
+	Message string
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid selector %q at position %d: %s", e.Input, e.Offset+1, e.Message)
+}
+
+// ParseSelector parses a Kubernetes-style selector over ClaimEntry fields
+// (JSON names) and claim parameters (param.<key>). Requirements are
+// separated by commas and combined with AND:
+//
+//	namespace!=default,createdBy=patrick
+//	template in (harvestervm,volumeclaim),status notin (deleted)
+//	repository,!path
+//
+// "=" and "==" are equivalent; a bare field tests that it is set and "!field"
+// that it is empty.
+func ParseSelector(input string) (Selector, error) {
+	p := &selectorParser{input: input}
+	p.next()
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+
+	var sel Selector
+	for {
+		r, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, r)
+
+		switch p.tok.kind {
+		case tokEOF:
+			return sel, nil
+		case tokComma:
+			p.next()
+		default:
+			return nil, p.errorf("expected ',' or end of selector, found %s", p.tok)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokComma
+	tokOpen
+	tokClose
+	tokEquals
+	tokNotEquals
+	tokBang
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of selector"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+	tok   token
+}
+
+// next advances to the next token.
+func (p *selectorParser) next() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokEOF, offset: start}
+		return
+	}
+
+	emit := func(kind tokenKind, n int) {
+		p.pos += n
+		p.tok = token{kind: kind, text: p.input[start:p.pos], offset: start}
+	}
+
+	rest := p.input[p.pos:]
+	switch {
+	case rest[0] == ',':
+		emit(tokComma, 1)
+	case rest[0] == '(':
+		emit(tokOpen, 1)
+	case rest[0] == ')':
+		emit(tokClose, 1)
+	case strings.HasPrefix(rest, "=="):
+		emit(tokEquals, 2)
+	case rest[0] == '=':
+		emit(tokEquals, 1)
+	case strings.HasPrefix(rest, "!="):
+		emit(tokNotEquals, 2)
+	case rest[0] == '!':
+		emit(tokBang, 1)
+	default:
+		n := strings.IndexAny(rest, " ,()=!")
+		if n < 0 {
+			n = len(rest)
+		}
+		emit(tokIdent, n)
+	}
+}
+
+func (p *selectorParser) errorf(format string, args ...any) error {
+	return &SelectorError{Input: p.input, Offset: p.tok.offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// field consumes a field name and checks that it exists.
+func (p *selectorParser) field() (string, error) {
+	if p.tok.kind != tokIdent {
+		return "", p.errorf("expected field name, found %s", p.tok)
+	}
+	name := p.tok.text
+	if !isField(name) {
+		return "", p.errorf("unknown field %q", name)
+	}
+	p.next()
+	return name, nil
+}
+
+// requirement parses one comma-separated term.
+func (p *selectorParser) requirement() (Requirement, error) {
+	if p.tok.kind == tokBang {
+		p.next()
+		f, err := p.field()
+		return Requirement{Field: f, Operator: OpDoesNotExist}, err
+	}
+
+	f, err := p.field()
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	switch p.tok.kind {
+	case tokEOF, tokComma:
+		return Requirement{Field: f, Operator: OpExists}, nil
+	case tokEquals, tokNotEquals:
+		op := OpEquals
+		if p.tok.kind == tokNotEquals {
+			op = OpNotEquals
+		}
+		p.next()
+		value := ""
+		if p.tok.kind == tokIdent {
+			value = p.tok.text
+			p.next()
+		} else if p.tok.kind != tokEOF && p.tok.kind != tokComma {
+			return Requirement{}, p.errorf("expected value, found %s", p.tok)
+		}
+		return Requirement{Field: f, Operator: op, Values: []string{value}}, nil
+	case tokIdent:
+		switch op := Operator(p.tok.text); op {
+		case OpIn, OpNotIn:
+			p.next()
+			values, err := p.valueSet()
+			return Requirement{Field: f, Operator: op, Values: values}, err
+		}
+	}
+	return Requirement{}, p.errorf("expected operator (=, ==, !=, in, notin) after %q, found %s", f, p.tok)
+}
+
+// valueSet parses a parenthesized, comma-separated list of values.
+func (p *selectorParser) valueSet() ([]string, error) {
+	if p.tok.kind != tokOpen {
+		return nil, p.errorf("expected '(', found %s", p.tok)
+	}
+	p.next()
+
+	var values []string
+	for {
+		if p.tok.kind != tokIdent {
+			return nil, p.errorf("expected value, found %s", p.tok)
+		}
+		values = append(values, p.tok.text)
+		p.next()
+
+		switch p.tok.kind {
+		case tokClose:
+			p.next()
+			return values, nil
+		case tokComma:
+			p.next()
+		default:
+			return nil, p.errorf("expected ',' or ')', found %s", p.tok)
+		}
+	}
+}
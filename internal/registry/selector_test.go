@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Selector
+	}{
+		{"", nil},
+		{"namespace!=default,createdBy=patrick", Selector{
+			{Field: "namespace", Operator: OpNotEquals, Values: []string{"default"}},
+			{Field: "createdBy", Operator: OpEquals, Values: []string{"patrick"}},
+		}},
+		{"status==active", Selector{
+			{Field: "status", Operator: OpEquals, Values: []string{"active"}},
+		}},
+		{"template in (harvestervm, volumeclaim)", Selector{
+			{Field: "template", Operator: OpIn, Values: []string{"harvestervm", "volumeclaim"}},
+		}},
+		{"status notin (deleted),repository,!path", Selector{
+			{Field: "status", Operator: OpNotIn, Values: []string{"deleted"}},
+			{Field: "repository", Operator: OpExists},
+			{Field: "path", Operator: OpDoesNotExist},
+		}},
+		{"createdAt=2026-02-05T10:58:33Z,namespace=", Selector{
+			{Field: "createdAt", Operator: OpEquals, Values: []string{"2026-02-05T10:58:33Z"}},
+			{Field: "namespace", Operator: OpEquals, Values: []string{""}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sel, err := ParseSelector(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sel)
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"owner=patrick", `invalid selector "owner=patrick" at position 1: unknown field "owner"`},
+		{"status=active,", `invalid selector "status=active," at position 15: expected field name, found end of selector`},
+		{"template in harvestervm", `invalid selector "template in harvestervm" at position 13: expected '(', found "harvestervm"`},
+		{"template in (a,b", `invalid selector "template in (a,b" at position 17: expected ',' or ')', found end of selector`},
+		{"template in ()", `invalid selector "template in ()" at position 14: expected value, found ")"`},
+		{"status active", `invalid selector "status active" at position 8: expected operator (=, ==, !=, in, notin) after "status", found "active"`},
+		{"status=(active)", `invalid selector "status=(active)" at position 8: expected value, found "("`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseSelector(tt.input)
+			var selErr *SelectorError
+			require.ErrorAs(t, err, &selErr)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestSelectorFilter(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+
+	filter := func(input string) []string {
+		sel, err := ParseSelector(input)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range sel.Filter(reg.Claims) {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"hacky", "harvestervm-developer-martin", "demo-project"}, filter(""))
+	assert.Equal(t, []string{"demo-project"}, filter("namespace!=default"))
+	assert.Equal(t, []string{"hacky", "harvestervm-developer-martin"}, filter("template in (harvestervm,volumeclaim),createdBy=patrick"))
+	assert.Equal(t, []string{"demo-project"}, filter("status notin (active)"))
+	assert.Nil(t, filter("!repository"))
+}
+
+func TestSelectorString(t *testing.T) {
+	input := "namespace!=default,template in (harvestervm,volumeclaim),source,!path"
+	sel, err := ParseSelector(input)
+	require.NoError(t, err)
+	assert.Equal(t, input, sel.String())
+}
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"slices"
+	"sync"
+)
+
+// IndexedFields lists the fields a Snapshot maintains exact-match indexes
+// for. Selector requirements on these fields are answered from the index.
+var IndexedFields = []string{"name", "template", "category", "namespace", "status", "source", "createdBy"}
+
+// Snapshot is an immutable, indexed view of a registry. It is built once
+// when a registry is swapped in and shared by all readers; neither the
+// snapshot nor the slices it returns may be modified.
+type Snapshot struct {
+	registry *ClaimRegistry
+	version  string
+	byName   map[string]int
+	byField  map[string]map[string][]int // field -> value -> positions, ascending
+
+	searchOnce sync.Once
+	search     *Index // built by the first Search
+}
+
+// NewSnapshot indexes reg. version identifies the content, e.g. for
+// pagination tokens. The full-text index is left to the first search, so
+// that swapping in a registry nobody searches stays cheap.
+func NewSnapshot(reg *ClaimRegistry, version string) *Snapshot {
+	s := &Snapshot{
+		registry: reg,
+		version:  version,
+		byName:   make(map[string]int, len(reg.Claims)),
+		byField:  make(map[string]map[string][]int, len(IndexedFields)),
+	}
+	for _, f := range IndexedFields {
+		s.byField[f] = map[string][]int{}
+	}
+	for i, e := range reg.Claims {
+		if _, dup := s.byName[e.Name]; !dup {
+			s.byName[e.Name] = i
+		}
+		for _, f := range IndexedFields {
+			v, _ := e.Field(f)
+			s.byField[f][v] = append(s.byField[f][v], i)
+		}
+	}
+	return s
+}
+
+// Registry returns the underlying registry.
+func (s *Snapshot) Registry() *ClaimRegistry {
+	return s.registry
+}
+
+// Version returns the content version the snapshot was built with.
+func (s *Snapshot) Version() string {
+	return s.version
+}
+
+// Claims returns all entries in registry order.
+func (s *Snapshot) Claims() []ClaimEntry {
+	return s.registry.Claims
+}
+
+// Len returns the number of entries.
+func (s *Snapshot) Len() int {
+	return len(s.registry.Claims)
+}
+
+// Get returns the entry with the given name.
+func (s *Snapshot) Get(name string) (ClaimEntry, bool) {
+	i, ok := s.byName[name]
+	if !ok {
+		return ClaimEntry{}, false
+	}
+	return s.registry.Claims[i], true
+}
+
+// Search runs a full-text query against the snapshot's search index.
+func (s *Snapshot) Search(query string, limit int) []Hit {
+	s.searchOnce.Do(func() { s.search = NewIndex(s.registry.Claims) })
+	return s.search.Search(query, limit)
+}
+
+// Select returns the entries matching sel in registry order. Equality and
+// set-membership requirements on indexed fields are answered by
+// intersecting their index entries; the remaining requirements are then
+// applied to the candidates.
+func (s *Snapshot) Select(sel Selector) []ClaimEntry {
+	var (
+		candidates []int
+		indexed    bool
+		residual   Selector
+	)
+	for _, r := range sel {
+		positions, ok := s.lookup(r)
+		if !ok {
+			residual = append(residual, r)
+			continue
+		}
+		if indexed {
+			positions = intersect(candidates, positions)
+		}
+		candidates, indexed = positions, true
+	}
+
+	if !indexed {
+		return sel.Filter(s.registry.Claims)
+	}
+
+	matches := residual.matcher()
+	var result []ClaimEntry
+	for _, i := range candidates {
+		if e := &s.registry.Claims[i]; matches(e) {
+			result = append(result, *e)
+		}
+	}
+	return result
+}
+
+// intersect returns the positions present in both ascending lists.
+func intersect(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// lookup returns the positions matching r from the field indexes, or false
+// when r cannot be answered from an index.
+func (s *Snapshot) lookup(r Requirement) ([]int, bool) {
+	index, ok := s.byField[r.Field]
+	if !ok {
+		return nil, false
+	}
+
+	switch r.Operator {
+	case OpEquals:
+		return index[r.Values[0]], true
+	case OpIn:
+		var positions []int
+		for _, v := range r.Values {
+			positions = append(positions, index[v]...)
+		}
+		slices.Sort(positions)
+		return slices.Compact(positions), true
+	}
+	return nil, false
+}
@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotGet(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+	snap := NewSnapshot(reg, "v1")
+
+	e, ok := snap.Get("demo-project")
+	require.True(t, ok)
+	assert.Equal(t, "harborproject", e.Template)
+
+	_, ok = snap.Get("nonexistent")
+	assert.False(t, ok)
+
+	assert.Equal(t, "v1", snap.Version())
+	assert.Equal(t, 3, snap.Len())
+}
+
+func TestSnapshotSelectMatchesFilter(t *testing.T) {
+	reg := syntheticRegistry(1000)
+	snap := NewSnapshot(reg, "")
+
+	for _, input := range []string{
+		"",
+		"category=infra",
+		"template in (volumeclaim,harborproject),status=active",
+		"namespace!=default,createdBy=user-7",
+		"status=active,repository=stuttgart-things/team-3",
+		"name=claim-00042",
+		"template in (nothing)",
+		"!path",
+	} {
+		t.Run(input, func(t *testing.T) {
+			sel, err := ParseSelector(input)
+			require.NoError(t, err)
+			assert.Equal(t, sel.Filter(reg.Claims), snap.Select(sel))
+		})
+	}
+}
+
+// syntheticRegistry generates n distinct claims spread over a handful of
+// templates, categories, namespaces, statuses, sources and owners.
+func syntheticRegistry(n int) *ClaimRegistry {
+	templates := []string{"volumeclaim", "harvestervm", "harborproject", "vspherevm", "postgresdb"}
+	categories := []string{"cli", "infra", "apps"}
+	namespaces := []string{"default", "harbor", "team-a", "team-b"}
+	sources := []string{"cli", "gitops", "api"}
+
+	reg := &ClaimRegistry{APIVersion: DefaultAPIVersion, Kind: DefaultKind, Claims: make([]ClaimEntry, n)}
+	for i := range reg.Claims {
+		reg.Claims[i] = ClaimEntry{
+			Name:       fmt.Sprintf("claim-%05d", i),
+			Template:   templates[i%len(templates)],
+			Category:   categories[i%len(categories)],
+			Namespace:  namespaces[i%len(namespaces)],
+			CreatedAt:  fmt.Sprintf("2026-02-%02dT10:00:00Z", 1+i%28),
+			CreatedBy:  fmt.Sprintf("user-%d", i%50),
+			Source:     sources[i%len(sources)],
+			Repository: fmt.Sprintf("stuttgart-things/team-%d", i%10),
+			Path:       fmt.Sprintf("claims/%s/claim-%05d.yaml", categories[i%len(categories)], i),
+			Status:     KnownStatuses[i%len(KnownStatuses)],
+		}
+	}
+	return reg
+}
+
+const benchmarkClaims = 100_000
+
+// benchmarkSnapshot builds the synthetic registry and its snapshot, then
+// collects the setup garbage so it does not skew the measured loop.
+func benchmarkSnapshot(b *testing.B) (*ClaimRegistry, *Snapshot) {
+	b.Helper()
+	reg := syntheticRegistry(benchmarkClaims)
+	snap := NewSnapshot(reg, "")
+	runtime.GC()
+	return reg, snap
+}
+
+func BenchmarkFindEntry(b *testing.B) {
+	reg, _ := benchmarkSnapshot(b)
+	name := reg.Claims[len(reg.Claims)-1].Name
+
+	for b.Loop() {
+		if FindEntry(reg, name) == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkSnapshotGet(b *testing.B) {
+	reg, snap := benchmarkSnapshot(b)
+	name := reg.Claims[len(reg.Claims)-1].Name
+
+	for b.Loop() {
+		if _, ok := snap.Get(name); !ok {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkFilterEntries(b *testing.B) {
+	reg, _ := benchmarkSnapshot(b)
+
+	for b.Loop() {
+		FilterEntries(reg, "infra", "harvestervm", "active", "")
+	}
+}
+
+func BenchmarkSelectorFilter(b *testing.B) {
+	reg, _ := benchmarkSnapshot(b)
+	sel, err := ParseSelector("category=infra,template=harvestervm,status=active")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		sel.Filter(reg.Claims)
+	}
+}
+
+func BenchmarkSnapshotSelect(b *testing.B) {
+	_, snap := benchmarkSnapshot(b)
+	sel, err := ParseSelector("category=infra,template=harvestervm,status=active")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		snap.Select(sel)
+	}
+}
+
+func BenchmarkSnapshotSelectByOwner(b *testing.B) {
+	_, snap := benchmarkSnapshot(b)
+	sel, err := ParseSelector("createdBy=user-7")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		snap.Select(sel)
+	}
+}
+
+func BenchmarkNewSnapshot(b *testing.B) {
+	reg := syntheticRegistry(benchmarkClaims)
+
+	for b.Loop() {
+		NewSnapshot(reg, "")
+	}
+}
+
+func BenchmarkSnapshotSearch(b *testing.B) {
+	_, snap := benchmarkSnapshot(b)
+	snap.Search("user", 1) // build the index outside the loop
+
+	for b.Loop() {
+		snap.Search("04217", 20)
+	}
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseFields parses a comma-separated list of field names such as
+// "name,template,param.cpu". It returns nil for an empty list.
+func ParseFields(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	var fields []string
+	for part := range strings.SplitSeq(spec, ",") {
+		name := strings.TrimSpace(part)
+		if !isField(name) {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// SortKey orders entries by a single field.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// String renders the key in the form accepted by ParseSort.
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + k.Field
+	}
+	return k.Field
+}
+
+// ParseSort parses a comma-separated sort specification such as
+// "createdAt,-name". A leading "-" sorts that field descending.
+func ParseSort(spec string) ([]SortKey, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		k := SortKey{Field: part}
+		if strings.HasPrefix(part, "-") {
+			k = SortKey{Field: part[1:], Desc: true}
+		} else if strings.HasPrefix(part, "+") {
+			k.Field = part[1:]
+		}
+		if !isField(k.Field) {
+			return nil, fmt.Errorf("unknown sort field %q", k.Field)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// SortEntries sorts entries in place by keys. The sort is stable, so entries
+// that compare equal keep their registry order.
+func SortEntries(entries []ClaimEntry, keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	gets := make([]func(*ClaimEntry) string, len(keys))
+	for i, k := range keys {
+		gets[i] = fieldGetter(k.Field)
+	}
+	slices.SortStableFunc(entries, func(a, b ClaimEntry) int {
+		for i, k := range keys {
+			if c := cmp.Compare(gets[i](&a), gets[i](&b)); c != 0 {
+				if k.Desc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+}
@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimEntryField(t *testing.T) {
+	e := ClaimEntry{Name: "hacky", CreatedAt: "2026-02-05T10:58:33Z"}
+
+	v, ok := e.Field("createdAt")
+	assert.True(t, ok)
+	assert.Equal(t, "2026-02-05T10:58:33Z", v)
+
+	_, ok = e.Field("CreatedAt")
+	assert.False(t, ok)
+}
+
+func TestParseSort(t *testing.T) {
+	keys, err := ParseSort("createdAt, -name,+status")
+	require.NoError(t, err)
+	assert.Equal(t, []SortKey{
+		{Field: "createdAt"},
+		{Field: "name", Desc: true},
+		{Field: "status"},
+	}, keys)
+
+	keys, err = ParseSort("")
+	require.NoError(t, err)
+	assert.Nil(t, keys)
+
+	_, err = ParseSort("createdAt,owner")
+	assert.EqualError(t, err, `unknown sort field "owner"`)
+}
+
+func TestSortEntries(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+
+	names := func(entries []ClaimEntry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	entries := append([]ClaimEntry(nil), reg.Claims...)
+	SortEntries(entries, []SortKey{{Field: "createdAt", Desc: true}})
+	assert.Equal(t, []string{"demo-project", "harvestervm-developer-martin", "hacky"}, names(entries))
+
+	// Ties keep registry order unless broken by a later key
+	SortEntries(entries, []SortKey{{Field: "category"}})
+	assert.Equal(t, []string{"harvestervm-developer-martin", "hacky", "demo-project"}, names(entries))
+
+	SortEntries(entries, []SortKey{{Field: "category"}, {Field: "name"}})
+	assert.Equal(t, []string{"hacky", "harvestervm-developer-martin", "demo-project"}, names(entries))
+}
+
+func TestClaimFields(t *testing.T) {
+	var names, omitEmpty []string
+	for _, f := range claimFields {
+		names = append(names, f.name)
+		if f.omitEmpty {
+			omitEmpty = append(omitEmpty, f.name)
+		}
+	}
+	assert.Equal(t, []string{
+		"name", "template", "category", "namespace", "createdAt", "createdBy",
+		"source", "repository", "path", "status", "registry", "parameters",
+	}, names)
+	assert.Equal(t, []string{"registry", "parameters"}, omitEmpty)
+	assert.Equal(t, names[:len(names)-1], FieldNames(), "every field but parameters is a string")
+
+	e := ClaimEntry{
+		Name: "n", Template: "t", Category: "c", Namespace: "ns", CreatedAt: "at", CreatedBy: "by",
+		Source: "s", Repository: "r", Path: "p", Status: "st", Registry: "reg",
+		Parameters: map[string]any{"disk": map[string]any{"size": "20Gi"}},
+	}
+	for name, want := range map[string]string{
+		"name": "n", "template": "t", "category": "c", "namespace": "ns", "createdAt": "at", "createdBy": "by",
+		"source": "s", "repository": "r", "path": "p", "status": "st", "registry": "reg",
+		"param.disk.size": "20Gi", "param.cpu": "",
+	} {
+		got, ok := e.Field(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, got, name)
+	}
+	for _, name := range []string{"parameters", "param.", "Name", "owner"} {
+		_, ok := e.Field(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("name, template,param.disk.size")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "template", "param.disk.size"}, fields)
+
+	fields, err = ParseFields("")
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	_, err = ParseFields("name,owner")
+	assert.EqualError(t, err, `unknown field "owner"`)
+	_, err = ParseFields("name,")
+	assert.EqualError(t, err, `unknown field ""`)
+	for _, spec := range []string{"parameters", "param.", "Name", "name,createdat"} {
+		_, err = ParseFields(spec)
+		assert.ErrorContains(t, err, "unknown field", spec)
+	}
+
+	assert.Equal(t, "name", FieldNames()[0])
+	assert.NotContains(t, FieldNames(), "parameters", "only string fields")
+}
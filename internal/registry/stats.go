@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GroupableFields lists the fields claims can be grouped by.
+var GroupableFields = []string{"template", "category", "status", "source", "namespace", "createdBy"}
+
+// Group is the number of claims sharing one combination of field values.
+type Group struct {
+	Keys  map[string]string `json:"keys"`
+	Count int               `json:"count"`
+}
+
+// ParseGroupBy parses a comma-separated list of groupable fields such as
+// "template,status".
+func ParseGroupBy(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if !slices.Contains(GroupableFields, f) {
+			return nil, fmt.Errorf("cannot group by %q (expected one of %s)", f, strings.Join(GroupableFields, ", "))
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// GroupCounts counts entries per distinct combination of the given fields.
+// Groups are ordered by count, largest first, then by their values.
+func GroupCounts(entries []ClaimEntry, fields []string) []Group {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	type group struct {
+		values []string
+		count  int
+	}
+	byKey := map[string]*group{}
+	for i := range entries {
+		values := make([]string, len(fields))
+		for j, f := range fields {
+			values[j], _ = entries[i].Field(f)
+		}
+		key := strings.Join(values, "\x00")
+		if g, ok := byKey[key]; ok {
+			g.count++
+			continue
+		}
+		byKey[key] = &group{values: values, count: 1}
+	}
+
+	groups := make([]*group, 0, len(byKey))
+	for _, g := range byKey {
+		groups = append(groups, g)
+	}
+	slices.SortFunc(groups, func(a, b *group) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return slices.Compare(a.values, b.values)
+	})
+
+	out := make([]Group, len(groups))
+	for i, g := range groups {
+		keys := make(map[string]string, len(fields))
+		for j, f := range fields {
+			keys[f] = g.values[j]
+		}
+		out[i] = Group{Keys: keys, Count: g.count}
+	}
+	return out
+}
+
+// Interval is the bucket width of a creation-time histogram.
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// ParseInterval validates a histogram interval name.
+func ParseInterval(v string) (Interval, error) {
+	switch i := Interval(v); i {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return i, nil
+	default:
+		return "", fmt.Errorf("unknown interval %q (expected day, week or month)", v)
+	}
+}
+
+// truncate returns the start of the bucket containing t (UTC). Weeks start
+// on Monday.
+func (i Interval) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch i {
+	case IntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// next returns the start of the bucket following start.
+func (i Interval) next(start time.Time) time.Time {
+	switch i {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Bucket counts the claims created within one interval starting at Start.
+type Bucket struct {
+	Start string `json:"start"` // YYYY-MM-DD (UTC)
+	Count int    `json:"count"`
+}
+
+// MaxBuckets bounds the length of a histogram.
+const MaxBuckets = 5000
+
+// Histogram buckets entries by CreatedAt. Buckets span the oldest to the
+// newest claim without gaps, so empty intervals are reported with a zero
+// count. Entries without a valid RFC3339 createdAt are counted as undated.
+// An error is returned when the range would need more than MaxBuckets.
+func Histogram(entries []ClaimEntry, interval Interval) (buckets []Bucket, undated int, err error) {
+	counts := map[time.Time]int{}
+	var first, last time.Time
+	for _, e := range entries {
+		t, err := time.Parse(time.RFC3339, e.CreatedAt)
+		if err != nil {
+			undated++
+			continue
+		}
+		start := interval.truncate(t)
+		if len(counts) == 0 || start.Before(first) {
+			first = start
+		}
+		if len(counts) == 0 || start.After(last) {
+			last = start
+		}
+		counts[start]++
+	}
+
+	if len(counts) == 0 {
+		return []Bucket{}, undated, nil
+	}
+	for t := first; !t.After(last); t = interval.next(t) {
+		if len(buckets) == MaxBuckets {
+			return nil, undated, fmt.Errorf("createdAt range %s to %s exceeds %d %s buckets, use a coarser interval",
+				first.Format(time.DateOnly), last.Format(time.DateOnly), MaxBuckets, interval)
+		}
+		buckets = append(buckets, Bucket{Start: t.Format(time.DateOnly), Count: counts[t]})
+	}
+	return buckets, undated, nil
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	fields, err := ParseGroupBy("template, status,template")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"template", "status"}, fields)
+
+	fields, err = ParseGroupBy("")
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	_, err = ParseGroupBy("path")
+	assert.ErrorContains(t, err, `cannot group by "path"`)
+}
+
+func TestGroupCounts(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+
+	groups := GroupCounts(reg.Claims, []string{"category"})
+	assert.Equal(t, []Group{
+		{Keys: map[string]string{"category": "cli"}, Count: 2},
+		{Keys: map[string]string{"category": "infra"}, Count: 1},
+	}, groups)
+
+	groups = GroupCounts(reg.Claims, []string{"createdBy", "status"})
+	assert.Equal(t, []Group{
+		{Keys: map[string]string{"createdBy": "patrick", "status": "active"}, Count: 2},
+		{Keys: map[string]string{"createdBy": "cli", "status": "inactive"}, Count: 1},
+	}, groups)
+
+	assert.Nil(t, GroupCounts(reg.Claims, nil))
+}
+
+func TestHistogram(t *testing.T) {
+	entries := []ClaimEntry{
+		{Name: "a", CreatedAt: "2026-02-02T08:00:00Z"}, // Monday
+		{Name: "b", CreatedAt: "2026-02-08T23:00:00Z"}, // Sunday, same week
+		{Name: "c", CreatedAt: "2026-02-20T10:00:00+02:00"},
+		{Name: "d", CreatedAt: "2026-04-01T00:00:00Z"},
+		{Name: "e"},
+		{Name: "f", CreatedAt: "yesterday"},
+	}
+
+	buckets, undated, err := Histogram(entries, IntervalWeek)
+	require.NoError(t, err)
+	assert.Equal(t, 2, undated)
+	require.Len(t, buckets, 9)
+	assert.Equal(t, Bucket{Start: "2026-02-02", Count: 2}, buckets[0])
+	assert.Equal(t, Bucket{Start: "2026-02-09", Count: 0}, buckets[1])
+	assert.Equal(t, Bucket{Start: "2026-02-16", Count: 1}, buckets[2])
+	assert.Equal(t, Bucket{Start: "2026-03-30", Count: 1}, buckets[8])
+
+	buckets, _, err = Histogram(entries, IntervalMonth)
+	require.NoError(t, err)
+	assert.Equal(t, []Bucket{
+		{Start: "2026-02-01", Count: 3},
+		{Start: "2026-03-01", Count: 0},
+		{Start: "2026-04-01", Count: 1},
+	}, buckets)
+
+	buckets, _, err = Histogram(entries[:2], IntervalDay)
+	require.NoError(t, err)
+	assert.Len(t, buckets, 7)
+
+	buckets, undated, err = Histogram(entries[4:], IntervalDay)
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+	assert.Equal(t, 2, undated)
+}
+
+func TestHistogramTooManyBuckets(t *testing.T) {
+	entries := []ClaimEntry{
+		{CreatedAt: "1990-01-01T00:00:00Z"},
+		{CreatedAt: "2026-01-01T00:00:00Z"},
+	}
+
+	_, _, err := Histogram(entries, IntervalDay)
+	assert.ErrorContains(t, err, "use a coarser interval")
+
+	buckets, _, err := Histogram(entries, IntervalMonth)
+	require.NoError(t, err)
+	assert.Len(t, buckets, 36*12+1)
+}
+
+func TestParseInterval(t *testing.T) {
+	i, err := ParseInterval("week")
+	require.NoError(t, err)
+	assert.Equal(t, IntervalWeek, i)
+
+	_, err = ParseInterval("year")
+	assert.Error(t, err)
+}
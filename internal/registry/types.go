@@ -19,4 +19,9 @@ type ClaimEntry struct {
 	Repository string `yaml:"repository" json:"repository"`
 	Path       string `yaml:"path" json:"path"`
 	Status     string `yaml:"status" json:"status"`
+	Registry   string `yaml:"registry,omitempty" json:"registry,omitempty"` // Origin registry in a federated view
+
+	// Parameters holds spec.parameters of the claim manifest when parameter
+	// crawling is enabled; it is not part of the registry file.
+	Parameters map[string]any `yaml:"-" json:"parameters,omitempty"`
 }
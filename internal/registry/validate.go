@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity classifies a validation finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Validation rule identifiers.
+const (
+	RuleAPIVersion       = "api-version"
+	RuleKind             = "kind"
+	RuleRequiredField    = "required-field"
+	RuleRecommendedField = "recommended-field"
+	RuleDuplicateName    = "duplicate-name"
+	RuleInvalidTimestamp = "invalid-timestamp"
+	RuleUnknownStatus    = "unknown-status"
+)
+
+// KnownStatuses lists the conventional values of ClaimEntry.Status. Other
+// values are reported as warnings, since registries may use their own.
+var KnownStatuses = []string{"active", "inactive", "pending", "deleted"}
+
+// Violation is a single validation finding. Path locates the offending
+// value, e.g. "claims[2].createdAt".
+type Violation struct {
+	Path     string   `json:"path"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Claim    string   `json:"claim,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+}
+
+// Violations is a list of validation findings.
+type Violations []Violation
+
+// HasErrors reports whether any finding has error severity.
+func (v Violations) HasErrors() bool {
+	for _, f := range v {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorCount returns the number of findings with error severity.
+func (v Violations) ErrorCount() int {
+	n := 0
+	for _, f := range v {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// ValidationError is returned when a registry fails validation.
+type ValidationError struct {
+	Violations Violations
+}
+
+func (e *ValidationError) Error() string {
+	for _, v := range e.Violations {
+		if v.Severity == SeverityError {
+			return fmt.Sprintf("registry validation failed with %d error(s), first: %s: %s",
+				e.Violations.ErrorCount(), v.Path, v.Message)
+		}
+	}
+	return "registry validation failed"
+}
+
+// Validate checks a parsed registry against the schema rules: expected
+// apiVersion and kind, required claim fields, unique names, RFC3339
+// createdAt timestamps and known status values.
+func Validate(reg *ClaimRegistry) Violations {
+	var out Violations
+
+	if reg.APIVersion != DefaultAPIVersion {
+		out = append(out, Violation{
+			Path:     "apiVersion",
+			Rule:     RuleAPIVersion,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("apiVersion must be %q, got %q", DefaultAPIVersion, reg.APIVersion),
+		})
+	}
+	if reg.Kind != DefaultKind {
+		out = append(out, Violation{
+			Path:     "kind",
+			Rule:     RuleKind,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("kind must be %q, got %q", DefaultKind, reg.Kind),
+		})
+	}
+
+	seen := make(map[string]int, len(reg.Claims))
+	for i, e := range reg.Claims {
+		at := func(field string) string { return fmt.Sprintf("claims[%d].%s", i, field) }
+		add := func(field, rule string, sev Severity, format string, args ...any) {
+			out = append(out, Violation{
+				Path:     at(field),
+				Rule:     rule,
+				Severity: sev,
+				Message:  fmt.Sprintf(format, args...),
+				Claim:    e.Name,
+			})
+		}
+
+		required := []struct{ field, value string }{
+			{"name", e.Name},
+			{"template", e.Template},
+			{"category", e.Category},
+			{"status", e.Status},
+		}
+		for _, f := range required {
+			if strings.TrimSpace(f.value) == "" {
+				add(f.field, RuleRequiredField, SeverityError, "%s is required", f.field)
+			}
+		}
+
+		recommended := []struct{ field, value string }{
+			{"namespace", e.Namespace},
+			{"createdAt", e.CreatedAt},
+			{"createdBy", e.CreatedBy},
+			{"source", e.Source},
+			{"repository", e.Repository},
+			{"path", e.Path},
+		}
+		for _, f := range recommended {
+			if strings.TrimSpace(f.value) == "" {
+				add(f.field, RuleRecommendedField, SeverityWarning, "%s is not set", f.field)
+			}
+		}
+
+		if e.Name != "" {
+			if first, dup := seen[e.Name]; dup {
+				add("name", RuleDuplicateName, SeverityError, "duplicate claim name %q (first defined at claims[%d])", e.Name, first)
+			} else {
+				seen[e.Name] = i
+			}
+		}
+
+		if e.CreatedAt != "" {
+			if _, err := time.Parse(time.RFC3339, e.CreatedAt); err != nil {
+				add("createdAt", RuleInvalidTimestamp, SeverityError, "createdAt %q is not an RFC3339 timestamp", e.CreatedAt)
+			}
+		}
+
+		if e.Status != "" && !slices.Contains(KnownStatuses, e.Status) {
+			add("status", RuleUnknownStatus, SeverityWarning, "status %q is not one of %s", e.Status, strings.Join(KnownStatuses, ", "))
+		}
+	}
+
+	return out
+}
+
+// Check parses and validates raw registry YAML, annotating each violation
+// with its line and column in the source. It is the single entry point used
+// by both the syncer and the validate command so they never disagree. An
+// error is returned only when data is not parseable YAML.
+func Check(data []byte) (*ClaimRegistry, Violations, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("parsing registry data: %w", err)
+	}
+
+	var reg ClaimRegistry
+	if err := root.Decode(&reg); err != nil {
+		return nil, nil, fmt.Errorf("parsing registry data: %w", err)
+	}
+
+	violations := Validate(&reg)
+	for i := range violations {
+		if n := locate(&root, violations[i].Path); n != nil {
+			violations[i].Line = n.Line
+			violations[i].Column = n.Column
+		}
+	}
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return &reg, violations, nil
+}
+
+// locate resolves a violation path such as "claims[2].createdAt" against a
+// YAML node tree. When the path does not exist (e.g. a missing required
+// field) the closest existing ancestor is returned.
+func locate(root *yaml.Node, path string) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+
+	for _, seg := range strings.Split(path, ".") {
+		key, index := seg, -1
+		if open := strings.IndexByte(seg, '['); open >= 0 && strings.HasSuffix(seg, "]") {
+			n, err := strconv.Atoi(seg[open+1 : len(seg)-1])
+			if err != nil {
+				return node
+			}
+			key, index = seg[:open], n
+		}
+
+		next := mappingValue(node, key)
+		if next == nil {
+			return node
+		}
+		if index >= 0 {
+			if next.Kind != yaml.SequenceNode || index >= len(next.Content) {
+				return next
+			}
+			next = next.Content[index]
+		}
+		node = next
+	}
+	return node
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateValid(t *testing.T) {
+	reg, err := ParseData([]byte(testYAML))
+	require.NoError(t, err)
+
+	violations := Validate(reg)
+	assert.Empty(t, violations)
+	assert.False(t, violations.HasErrors())
+}
+
+func TestValidateViolations(t *testing.T) {
+	reg, err := ParseData([]byte(`
+apiVersion: claim-registry.io/v2
+kind: Registry
+claims:
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    namespace: default
+    createdAt: "2026-02-05T10:58:33Z"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/hacky.yaml
+    status: active
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    namespace: default
+    createdAt: "yesterday"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/hacky.yaml
+    status: archived
+  - template: harvestervm
+    category: cli
+    status: active
+`))
+	require.NoError(t, err)
+
+	violations := Validate(reg)
+	assert.True(t, violations.HasErrors())
+
+	byPath := map[string]Violation{}
+	for _, v := range violations {
+		byPath[v.Path+"/"+v.Rule] = v
+	}
+
+	assert.Contains(t, byPath, "apiVersion/"+RuleAPIVersion)
+	assert.Contains(t, byPath, "kind/"+RuleKind)
+	assert.Contains(t, byPath, "claims[1].name/"+RuleDuplicateName)
+	assert.Contains(t, byPath, "claims[1].createdAt/"+RuleInvalidTimestamp)
+	assert.Contains(t, byPath, "claims[1].status/"+RuleUnknownStatus)
+	assert.Contains(t, byPath, "claims[2].name/"+RuleRequiredField)
+	assert.Equal(t, "hacky", byPath["claims[1].status/"+RuleUnknownStatus].Claim)
+	assert.Equal(t, SeverityWarning, byPath["claims[1].status/"+RuleUnknownStatus].Severity)
+
+	warning := byPath["claims[2].namespace/"+RuleRecommendedField]
+	assert.Equal(t, SeverityWarning, warning.Severity)
+
+	assert.Equal(t, 5, violations.ErrorCount())
+
+	err = &ValidationError{Violations: violations}
+	assert.Contains(t, err.Error(), "5 error(s)")
+}
+
+func TestCheckLineNumbers(t *testing.T) {
+	data := []byte(`apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    template: volumeclaim
+    category: cli
+    namespace: default
+    createdAt: "yesterday"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/hacky.yaml
+    status: active
+  - name: missing-template
+    category: cli
+    namespace: default
+    createdAt: "2026-02-05T10:58:33Z"
+    createdBy: patrick
+    source: cli
+    repository: stuttgart-things/harvester
+    path: claims/cli/missing-template.yaml
+    status: active
+`)
+
+	reg, violations, err := Check(data)
+	require.NoError(t, err)
+	require.NotNil(t, reg)
+	require.Len(t, violations, 2)
+
+	assert.Equal(t, "claims[0].createdAt", violations[0].Path)
+	assert.Equal(t, 8, violations[0].Line)
+	assert.Equal(t, 16, violations[0].Column)
+
+	// Missing fields point at the claim itself
+	assert.Equal(t, "claims[1].template", violations[1].Path)
+	assert.Equal(t, 14, violations[1].Line)
+}
+
+func TestCheckInvalidYAML(t *testing.T) {
+	_, _, err := Check([]byte(":::invalid"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Clock abstracts time for the sync loop so tests can drive it manually.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the wall-clock implementation of Clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// StatusError is returned by HTTP-based sources for unexpected responses.
+// RetryAfter carries the delay requested by the server via Retry-After or
+// X-RateLimit-Reset, or zero if none was given.
+type StatusError struct {
+	StatusCode int
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected status " + strconv.Itoa(e.StatusCode) + " from " + e.URL
+}
+
+// retryAfter extracts the server-requested retry delay from a response.
+// Retry-After may be delta-seconds or an HTTP date; X-RateLimit-Reset is the
+// Unix time at which GitHub's rate limit window resets and only applies once
+// X-RateLimit-Remaining has dropped to zero.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			return t.Sub(now)
+		}
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		if epoch, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if t := time.Unix(epoch, 0); t.After(now) {
+				return t.Sub(now)
+			}
+		}
+	}
+	return 0
+}
+
+// equalJitter returns a random duration in [d/2, d].
+func equalJitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half+1)
+}
+
+// nextDelay returns how long to wait before the next attempt. After a
+// success it is the polling interval; after failures it doubles per
+// consecutive failure up to MaxBackoff, is jittered so replicas spread out,
+// and never undercuts a server-requested retry delay.
+func (s *Syncer) nextDelay(failures int, err error) time.Duration {
+	if failures == 0 {
+		return s.cfg.Interval
+	}
+
+	d := s.cfg.Interval
+	for i := 0; i < failures && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+	d = s.jitter(d)
+
+	var se *StatusError
+	if errors.As(err, &se) && se.RetryAfter > d {
+		d = se.RetryAfter
+	}
+	return d
+}
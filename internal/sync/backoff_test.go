@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock hands every requested wait to the test, which fires it manually.
+type fakeClock struct {
+	now   time.Time
+	waits chan fakeWait
+}
+
+type fakeWait struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:   time.Date(2026, 2, 5, 10, 0, 0, 0, time.UTC),
+		waits: make(chan fakeWait, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.waits <- fakeWait{d: d, ch: ch}
+	return ch
+}
+
+// next returns the delay of the next scheduled wait and fires it.
+func (c *fakeClock) next(t *testing.T) time.Duration {
+	t.Helper()
+	select {
+	case w := <-c.waits:
+		w.ch <- c.now
+		return w.d
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for sync loop to schedule")
+		return 0
+	}
+}
+
+func TestBackoffOnFailure(t *testing.T) {
+	responses := make(chan func(w http.ResponseWriter), 8)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(<-responses)(w)
+	}))
+	defer ts.Close()
+
+	ok := func(w http.ResponseWriter) { w.Write([]byte(testRegistryYAML)) }
+	status := func(code int, header ...string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			for i := 0; i+1 < len(header); i += 2 {
+				w.Header().Set(header[i], header[i+1])
+			}
+			w.WriteHeader(code)
+		}
+	}
+
+	clock := newFakeClock()
+	s := NewSyncer(Config{
+		Repo:       "test/repo",
+		BaseURL:    ts.URL,
+		Interval:   10 * time.Second,
+		MaxBackoff: time.Minute,
+		Clock:      clock,
+	})
+	s.jitter = func(d time.Duration) time.Duration { return d }
+
+	responses <- ok
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	s.Start(context.Background())
+	defer s.Stop()
+
+	responses <- status(http.StatusInternalServerError)
+	responses <- status(http.StatusBadGateway)
+	responses <- status(http.StatusServiceUnavailable)
+	responses <- status(http.StatusTooManyRequests, "Retry-After", "300")
+	responses <- ok
+
+	assert.Equal(t, 10*time.Second, clock.next(t), "normal interval")
+	assert.Equal(t, 20*time.Second, clock.next(t), "first failure")
+	assert.Equal(t, 40*time.Second, clock.next(t), "second failure")
+	assert.Equal(t, time.Minute, clock.next(t), "capped at MaxBackoff")
+	assert.Equal(t, 5*time.Minute, clock.next(t), "Retry-After honored")
+	assert.Equal(t, 10*time.Second, clock.next(t), "back to interval after success")
+
+	assert.Equal(t, 0, s.Status().ConsecutiveFailures)
+}
+
+func TestNextDelayJitter(t *testing.T) {
+	s := NewSyncer(Config{Repo: "test/repo", Interval: 10 * time.Second})
+
+	for i := 0; i < 100; i++ {
+		d := s.nextDelay(1, nil)
+		assert.GreaterOrEqual(t, d, 10*time.Second)
+		assert.LessOrEqual(t, d, 20*time.Second)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 2, 5, 10, 0, 0, 0, time.UTC)
+
+	h := http.Header{}
+	h.Set("Retry-After", "120")
+	assert.Equal(t, 2*time.Minute, retryAfter(h, now))
+
+	h = http.Header{}
+	h.Set("Retry-After", now.Add(90*time.Second).Format(http.TimeFormat))
+	assert.Equal(t, 90*time.Second, retryAfter(h, now))
+
+	h = http.Header{}
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(15*time.Minute).Unix(), 10))
+	assert.Equal(t, 15*time.Minute, retryAfter(h, now))
+
+	// Reset is ignored while requests remain
+	h.Set("X-RateLimit-Remaining", "12")
+	assert.Equal(t, time.Duration(0), retryAfter(h, now))
+
+	assert.Equal(t, time.Duration(0), retryAfter(http.Header{}, now))
+}
+
+func TestRateLimitStatusError(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL, Interval: time.Second})
+	s.jitter = func(d time.Duration) time.Duration { return d }
+
+	delay := s.sync(context.Background())
+	assert.Greater(t, delay, 9*time.Minute)
+	assert.LessOrEqual(t, delay, 10*time.Minute)
+}
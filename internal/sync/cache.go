@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// cachedSnapshot is the on-disk format of Config.CacheFile.
+type cachedSnapshot struct {
+	Registry  string    `json:"registry"`
+	Source    string    `json:"source"`
+	Revision  string    `json:"revision"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	Data      string    `json:"data"` // registry file content as fetched
+}
+
+// saveCache persists validated registry content so that a later process can
+// start from it when the source is unreachable. The file is replaced
+// atomically; failures are logged and otherwise ignored.
+func (s *Syncer) saveCache(res *FetchResult, fetchedAt time.Time) {
+	if s.cfg.CacheFile == "" {
+		return
+	}
+	if err := writeCache(s.cfg.CacheFile, cachedSnapshot{
+		Registry:  s.Name(),
+		Source:    s.cfg.Source.String(),
+		Revision:  res.Revision,
+		FetchedAt: fetchedAt,
+		Data:      string(res.Data),
+	}); err != nil {
+		s.log.Warn("writing snapshot cache failed", "file", s.cfg.CacheFile, logging.Err(err))
+	}
+}
+
+func writeCache(path string, snap cachedSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadCache swaps in the snapshot persisted by saveCache. The syncer then
+// reports StateCached until a live sync succeeds.
+func (s *Syncer) loadCache() error {
+	if s.cfg.CacheFile == "" {
+		return errors.New("no cache configured")
+	}
+	data, err := os.ReadFile(s.cfg.CacheFile)
+	if err != nil {
+		return err
+	}
+	var snap cachedSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("parsing %s: %w", s.cfg.CacheFile, err)
+	}
+	if snap.Source != s.cfg.Source.String() {
+		return fmt.Errorf("%s holds a snapshot of %s", s.cfg.CacheFile, snap.Source)
+	}
+
+	// The content was valid when cached, but the rules may have changed.
+	reg, violations, err := registry.Check([]byte(snap.Data))
+	if err != nil {
+		return err
+	}
+	if violations.HasErrors() {
+		return &registry.ValidationError{Violations: violations}
+	}
+
+	s.mu.Lock()
+	s.registry = reg
+	s.revision = snap.Revision
+	s.hash = contentHash([]byte(snap.Data))
+	s.violations = violations
+	s.lastChanged = snap.FetchedAt
+	s.fromCache = true
+	s.mu.Unlock()
+
+	if s.onSwap != nil {
+		s.onSwap(Changeset{Registry: s.Name(), Revision: snap.Revision, Time: s.cfg.Clock.Now()})
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFallback(t *testing.T) {
+	fail, notModified := false, false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if notModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(testRegistryYAML))
+	}))
+	defer ts.Close()
+
+	cache := filepath.Join(t.TempDir(), "registries", "harvester.json")
+	cfg := Config{Repo: "test/repo", BaseURL: ts.URL, CacheFile: cache}
+
+	live := NewSyncer(cfg)
+	require.NoError(t, live.InitialSync(context.Background()))
+	assert.Equal(t, StateLive, live.Status().State)
+	require.FileExists(t, cache)
+
+	// A restart during an outage serves the persisted snapshot.
+	fail = true
+	var loaded []Changeset
+	s := NewSyncer(cfg)
+	s.onSwap = func(cs Changeset) { loaded = append(loaded, cs) }
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	st := s.Status()
+	assert.Equal(t, StateCached, st.State)
+	assert.True(t, st.Ready())
+	assert.Equal(t, 2, st.ClaimCount)
+	assert.Equal(t, live.Revision(), st.Revision)
+	assert.Equal(t, live.LastChanged().UTC(), st.LastChanged.UTC(), "lastChanged is the original fetch time")
+	assert.True(t, st.LastSuccess.IsZero())
+	assert.Contains(t, st.LastError, "unexpected status 502")
+	require.Len(t, loaded, 1)
+	assert.Empty(t, loaded[0].Changes)
+
+	// Failed retries keep serving from cache, the first live sync ends it.
+	s.sync(context.Background())
+	assert.Equal(t, StateCached, s.Status().State)
+	notModified = true
+	s.sync(context.Background())
+	assert.Equal(t, StateCached, s.Status().State, "a not-modified response delivers nothing to confirm")
+	fail, notModified = false, false
+	s.sync(context.Background())
+	st = s.Status()
+	assert.Equal(t, StateLive, st.State)
+	assert.False(t, st.LastSuccess.IsZero())
+}
+
+func TestCacheFallbackUnavailable(t *testing.T) {
+	ts := newTestServer(t, "unavailable", http.StatusServiceUnavailable)
+	defer ts.Close()
+	dir := t.TempDir()
+
+	// No cache file yet.
+	s := NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL, CacheFile: filepath.Join(dir, "missing.json")})
+	err := s.InitialSync(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 503")
+	assert.Equal(t, StatePending, s.Status().State)
+
+	// A cache written for a different source is not used.
+	other := filepath.Join(dir, "other.json")
+	require.NoError(t, writeCache(other, cachedSnapshot{Source: "file:///elsewhere.yaml", Data: testRegistryYAML}))
+	s = NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL, CacheFile: other})
+	assert.ErrorContains(t, s.InitialSync(context.Background()), "holds a snapshot of file:///elsewhere.yaml")
+
+	// Neither is a cache whose content no longer validates.
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, writeCache(invalid, cachedSnapshot{Source: s.cfg.Source.String(), Data: "apiVersion: v1\nkind: ConfigMap\n"}))
+	s = NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL, CacheFile: invalid})
+	assert.Error(t, s.InitialSync(context.Background()))
+	assert.Nil(t, s.GetRegistry())
+}
+
+func TestCacheKeepsLastGood(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	cache := filepath.Join(t.TempDir(), "harvester.json")
+	s := NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL, CacheFile: cache})
+	require.NoError(t, s.InitialSync(context.Background()))
+	before, err := os.ReadFile(cache)
+	require.NoError(t, err)
+
+	// Rejected content never replaces the cached snapshot.
+	body = "apiVersion: v1\nkind: ConfigMap\n"
+	s.sync(context.Background())
+	after, err := os.ReadFile(cache)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	entries, err := os.ReadDir(filepath.Dir(cache))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files left behind")
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// DefaultEventHistory is the number of events kept for resuming watches.
+const DefaultEventHistory = 1000
+
+var (
+	// ErrResourceVersionExpired is returned by EventLog.Since when events
+	// after the requested resource version are no longer retained, or the
+	// version was issued by a previous process. Clients must re-list.
+	ErrResourceVersionExpired = errors.New("resource version expired")
+	// ErrInvalidResourceVersion is returned for malformed resource versions.
+	ErrInvalidResourceVersion = errors.New("invalid resource version")
+)
+
+// Event is a claim change observed between two consecutive merged
+// snapshots.
+type Event struct {
+	Type            registry.ChangeType `json:"type"`
+	ResourceVersion string              `json:"resourceVersion"`
+	Object          registry.ClaimEntry `json:"object"`
+}
+
+// EventLog numbers claim changes and retains the most recent ones in a ring
+// buffer so that watchers can resume from a resource version. Resource
+// versions have the form "<epoch>-<sequence>"; the epoch identifies the
+// process, so versions from before a restart are reported as expired rather
+// than misinterpreted.
+type EventLog struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64  // sequence number of the newest event
+	ring    []Event // capacity-bounded history
+	start   int     // index of the oldest retained event
+	size    int     // number of retained events
+	changed chan struct{}
+}
+
+// NewEventLog creates an EventLog retaining up to capacity events.
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = DefaultEventHistory
+	}
+	return &EventLog{
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		ring:    make([]Event, capacity),
+		changed: make(chan struct{}),
+	}
+}
+
+// version renders the resource version for a sequence number.
+func (l *EventLog) version(seq uint64) string {
+	return l.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// ResourceVersion returns the version of the newest event.
+func (l *EventLog) ResourceVersion() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.version(l.seq)
+}
+
+// Append records changes as events and wakes up waiting watchers.
+func (l *EventLog) Append(changes []registry.Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range changes {
+		l.seq++
+		ev := Event{Type: c.Type, ResourceVersion: l.version(l.seq), Object: c.Claim}
+		if l.size < len(l.ring) {
+			l.ring[(l.start+l.size)%len(l.ring)] = ev
+			l.size++
+		} else {
+			l.ring[l.start] = ev
+			l.start = (l.start + 1) % len(l.ring)
+		}
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Since returns the events after resource version rv, oldest first, and a
+// channel that is closed when further events are appended.
+func (l *EventLog) Since(rv string) ([]Event, <-chan struct{}, error) {
+	epoch, seqStr, ok := strings.Cut(rv, "-")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if !ok || err != nil || epoch == "" {
+		return nil, nil, fmt.Errorf("%w %q", ErrInvalidResourceVersion, rv)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	oldest := l.seq - uint64(l.size) // events after oldest are retained
+	if epoch != l.epoch || seq > l.seq || seq < oldest {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceVersionExpired, rv)
+	}
+
+	events := make([]Event, 0, l.seq-seq)
+	for i := seq - oldest; i < uint64(l.size); i++ {
+		events = append(events, l.ring[(l.start+int(i))%len(l.ring)])
+	}
+	return events, l.changed, nil
+}
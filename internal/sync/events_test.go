@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+func added(names ...string) []registry.Change {
+	changes := make([]registry.Change, len(names))
+	for i, n := range names {
+		changes[i] = registry.Change{Type: registry.Added, Claim: registry.ClaimEntry{Name: n}}
+	}
+	return changes
+}
+
+func eventNames(events []Event) []string {
+	names := make([]string, len(events))
+	for i, ev := range events {
+		names[i] = ev.Object.Name
+	}
+	return names
+}
+
+func TestEventLogSince(t *testing.T) {
+	l := NewEventLog(10)
+	start := l.ResourceVersion()
+
+	events, wait, err := l.Since(start)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	l.Append(added("a", "b"))
+	select {
+	case <-wait:
+	default:
+		t.Fatal("wait channel not closed by Append")
+	}
+
+	events, _, err = l.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, eventNames(events))
+	assert.Equal(t, registry.Added, events[0].Type)
+	assert.Equal(t, l.ResourceVersion(), events[1].ResourceVersion)
+
+	events, _, err = l.Since(events[0].ResourceVersion)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, eventNames(events))
+}
+
+func TestEventLogRingBuffer(t *testing.T) {
+	l := NewEventLog(3)
+	start := l.ResourceVersion()
+	l.Append(added("a", "b"))
+	second := l.ResourceVersion()
+	l.Append(added("c", "d"))
+
+	_, _, err := l.Since(start)
+	assert.ErrorIs(t, err, ErrResourceVersionExpired, "a was evicted")
+
+	events, _, err := l.Since(second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, eventNames(events))
+
+	l.Append(added("e", "f", "g", "h"))
+	events, _, err = l.Since(l.ResourceVersion())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	_, _, err = l.Since(second)
+	assert.ErrorIs(t, err, ErrResourceVersionExpired)
+}
+
+func TestEventLogInvalidVersions(t *testing.T) {
+	l := NewEventLog(3)
+	l.Append(added("a"))
+
+	for _, rv := range []string{"", "abc", "-1", "x-y"} {
+		_, _, err := l.Since(rv)
+		assert.ErrorIs(t, err, ErrInvalidResourceVersion, rv)
+	}
+
+	_, _, err := l.Since("otherepoch-1")
+	assert.ErrorIs(t, err, ErrResourceVersionExpired, "version from another process")
+	_, _, err = l.Since(l.epoch + "-99")
+	assert.ErrorIs(t, err, ErrResourceVersionExpired, "version from the future")
+}
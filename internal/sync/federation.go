@@ -0,0 +1,378 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/metrics"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// ConflictPolicy decides what happens when several registries define a
+// claim with the same name.
+type ConflictPolicy string
+
+const (
+	// ConflictFirst keeps the entry from the registry configured first.
+	ConflictFirst ConflictPolicy = "first"
+	// ConflictLast keeps the entry from the registry configured last.
+	ConflictLast ConflictPolicy = "last"
+	// ConflictReject drops every entry with a conflicting name.
+	ConflictReject ConflictPolicy = "reject"
+)
+
+// ParseConflictPolicy validates a conflict policy name; empty selects
+// ConflictFirst.
+func ParseConflictPolicy(v string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(v); p {
+	case "":
+		return ConflictFirst, nil
+	case ConflictFirst, ConflictLast, ConflictReject:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q (expected first, last or reject)", v)
+	}
+}
+
+// FederationConfig holds federation configuration.
+type FederationConfig struct {
+	ConflictPolicy ConflictPolicy  // Duplicate name handling; defaults to ConflictFirst
+	Logger         *slog.Logger    // Defaults to slog.Default()
+	EventHistory   int             // Claim change events kept for watch resumption; defaults to DefaultEventHistory
+	HistorySize    int             // Changesets kept for the change history; defaults to DefaultHistorySize
+	OnChange       func(Changeset) // Called with every non-empty change of the merged view, e.g. to send notifications
+	Crawler        *ParamCrawler   // Attaches claim parameters to merged entries; nil disables crawling
+}
+
+// Conflict records a claim name defined by more than one registry.
+type Conflict struct {
+	Name       string   `json:"name"`
+	Registries []string `json:"registries"`
+	Winner     string   `json:"winner,omitempty"` // empty when the policy rejected all entries
+}
+
+// Federation syncs several registries independently and serves a merged
+// view in which every entry is annotated with its origin registry.
+type Federation struct {
+	cfg       FederationConfig
+	syncers   []*Syncer
+	snapshot  *registry.Snapshot // indexed merged view; nil until a registry loads
+	conflicts []Conflict
+	events    *EventLog // changes between consecutive merged views
+	history   *History  // changesets applied by member syncs
+	mu        sync.RWMutex
+	mergeMu   sync.Mutex // serializes merges triggered by concurrent member swaps
+}
+
+// NewFederation creates a Federation over the given syncers. The order of
+// syncers is the precedence order used by the conflict policy.
+func NewFederation(cfg FederationConfig, syncers ...*Syncer) *Federation {
+	if cfg.ConflictPolicy == "" {
+		cfg.ConflictPolicy = ConflictFirst
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	f := &Federation{
+		cfg:     cfg,
+		syncers: syncers,
+		events:  NewEventLog(cfg.EventHistory),
+		history: NewHistory(cfg.HistorySize),
+	}
+	for _, s := range syncers {
+		s.onSwap = f.swapped
+	}
+	if c := cfg.Crawler; c != nil {
+		c.refs = func() []paramRef {
+			if snap := f.Snapshot(); snap != nil {
+				return claimRefs(snap.Claims())
+			}
+			return nil
+		}
+		c.onChange = func() { f.merge(&Changeset{Time: time.Now()}) }
+	}
+	return f
+}
+
+// InitialSync performs the first sync of every registry. Registries that
+// fail are logged and retried by the background loop; an error is returned
+// only when no registry could be loaded.
+func (f *Federation) InitialSync(ctx context.Context) error {
+	var errs []error
+	for _, s := range f.syncers {
+		if err := s.InitialSync(ctx); err != nil {
+			f.cfg.Logger.Error("initial sync failed", logging.KeyRegistry, s.Name(), logging.Err(err))
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	if len(errs) == len(f.syncers) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Start begins the background loops of all registries and, if configured,
+// the parameter crawler.
+func (f *Federation) Start(ctx context.Context) {
+	for _, s := range f.syncers {
+		s.Start(ctx)
+	}
+	if f.cfg.Crawler != nil {
+		f.cfg.Crawler.start(ctx)
+	}
+}
+
+// Stop terminates all background loops and waits for them to finish.
+func (f *Federation) Stop() {
+	if f.cfg.Crawler != nil {
+		f.cfg.Crawler.stop()
+	}
+	for _, s := range f.syncers {
+		s.Stop()
+	}
+}
+
+// Syncers returns the member syncers in precedence order.
+func (f *Federation) Syncers() []*Syncer {
+	return f.syncers
+}
+
+// Trigger requests an out-of-band sync of every registry. It reports
+// whether at least one new sync was queued.
+func (f *Federation) Trigger() bool {
+	queued := false
+	for _, s := range f.syncers {
+		if s.Trigger() {
+			queued = true
+		}
+	}
+	return queued
+}
+
+// GetRegistry returns the current merged registry (thread-safe).
+func (f *Federation) GetRegistry() *registry.ClaimRegistry {
+	if snap := f.Snapshot(); snap != nil {
+		return snap.Registry()
+	}
+	return nil
+}
+
+// Snapshot returns the indexed merged view, or nil before the first registry
+// is loaded. Its version is derived from the members' content, so it only
+// changes when a registry changes and is identical across replicas serving
+// the same data.
+func (f *Federation) Snapshot() *registry.Snapshot {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot
+}
+
+// Current returns the merged view together with the resource version of the
+// newest event it reflects, so that a watch started from that version sees
+// every later change.
+func (f *Federation) Current() (*registry.Snapshot, string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshot, f.events.ResourceVersion()
+}
+
+// Events returns the log of claim changes between merged views.
+func (f *Federation) Events() *EventLog {
+	return f.events
+}
+
+// History returns the changesets applied by member syncs.
+func (f *Federation) History() *History {
+	return f.history
+}
+
+// swapped records the changes of a member sync and rebuilds the merged view.
+// The initial load of a member is not reported to OnChange.
+func (f *Federation) swapped(cs Changeset) {
+	if len(cs.Changes) == 0 {
+		f.merge(nil)
+		return
+	}
+	f.history.Add(cs)
+	f.merge(&cs)
+}
+
+// Conflicts returns the name conflicts found by the last merge.
+func (f *Federation) Conflicts() []Conflict {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.conflicts
+}
+
+// merge rebuilds the federated view from the members' current snapshots.
+// Unless trigger is nil, the changes to the merged view are reported to
+// OnChange with the registry and revisions of trigger, the member changeset
+// or crawl that caused the merge. Changes shadowed by a conflict winner are
+// thus not reported, and every reported claim carries its registry.
+func (f *Federation) merge(trigger *Changeset) {
+	f.mergeMu.Lock()
+	defer f.mergeMu.Unlock()
+
+	type origin struct {
+		entry    registry.ClaimEntry
+		registry string
+	}
+
+	var (
+		order  []string
+		byName = map[string][]origin{}
+		loaded bool
+		hashes strings.Builder
+	)
+	for _, s := range f.syncers {
+		reg, hash := s.snapshot()
+		if reg == nil {
+			continue
+		}
+		loaded = true
+		fmt.Fprintf(&hashes, "%s=%s\n", s.Name(), hash)
+		for _, e := range reg.Claims {
+			e.Registry = s.Name()
+			if f.cfg.Crawler != nil {
+				e.Parameters = f.cfg.Crawler.Params(&e)
+			}
+			if _, ok := byName[e.Name]; !ok {
+				order = append(order, e.Name)
+			}
+			byName[e.Name] = append(byName[e.Name], origin{entry: e, registry: s.Name()})
+		}
+	}
+
+	if !loaded {
+		return
+	}
+
+	merged := &registry.ClaimRegistry{
+		APIVersion: registry.DefaultAPIVersion,
+		Kind:       registry.DefaultKind,
+		Claims:     make([]registry.ClaimEntry, 0, len(order)),
+	}
+	var conflicts []Conflict
+
+	for _, name := range order {
+		origins := byName[name]
+		if len(origins) == 1 {
+			merged.Claims = append(merged.Claims, origins[0].entry)
+			continue
+		}
+
+		c := Conflict{Name: name}
+		for _, o := range origins {
+			c.Registries = append(c.Registries, o.registry)
+		}
+		switch f.cfg.ConflictPolicy {
+		case ConflictLast:
+			winner := origins[len(origins)-1]
+			c.Winner = winner.registry
+			merged.Claims = append(merged.Claims, winner.entry)
+		case ConflictReject:
+		default:
+			c.Winner = origins[0].registry
+			merged.Claims = append(merged.Claims, origins[0].entry)
+		}
+		conflicts = append(conflicts, c)
+	}
+
+	// Crawled parameters are part of the served content, so they count
+	// towards the version as well.
+	if f.cfg.Crawler != nil {
+		fmt.Fprintf(&hashes, "parameters=%s\n", f.cfg.Crawler.Hash())
+	}
+	version := shortHash(contentHash([]byte(string(f.cfg.ConflictPolicy) + "\n" + hashes.String())))
+	snapshot := registry.NewSnapshot(merged, version)
+
+	metrics.SetClaims(merged.Claims)
+
+	// The initial load is not reported as changes; watchers without a
+	// resource version receive the current state instead.
+	var changes []registry.Change
+	if prev := f.Snapshot(); prev != nil {
+		changes = registry.Diff(prev.Claims(), merged.Claims)
+	}
+
+	f.mu.Lock()
+	f.snapshot = snapshot
+	f.conflicts = conflicts
+	f.events.Append(changes)
+	f.mu.Unlock()
+
+	if trigger != nil && len(changes) > 0 && f.cfg.OnChange != nil {
+		cs := *trigger
+		cs.Changes = changes
+		f.cfg.OnChange(cs)
+	}
+
+	// New or moved claims may reference manifests not crawled yet.
+	if f.cfg.Crawler != nil && slices.ContainsFunc(changes, movedManifest) {
+		f.cfg.Crawler.Trigger()
+	}
+
+	if len(f.syncers) > 1 {
+		f.cfg.Logger.Info("registries merged",
+			logging.KeyClaimCount, len(merged.Claims),
+			"registries", len(f.syncers),
+			"conflicts", len(conflicts),
+			"policy", string(f.cfg.ConflictPolicy))
+	}
+}
+
+// movedManifest reports whether c adds a claim or changes the manifest it
+// references.
+func movedManifest(c registry.Change) bool {
+	if c.Type == registry.Added {
+		return true
+	}
+	return slices.ContainsFunc(c.Fields, func(fc registry.FieldChange) bool {
+		return fc.Field == "repository" || fc.Field == "path"
+	})
+}
+
+// FederationStatus describes the state of all registries in a Federation.
+type FederationStatus struct {
+	ClaimCount     int            `json:"claimCount"`
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy"`
+	Conflicts      []Conflict     `json:"conflicts,omitempty"`
+	Registries     []Status       `json:"registries"`
+	Degraded       []string       `json:"degraded,omitempty"` // Names of registries that are not ready
+}
+
+// Ready reports whether at least one registry is loaded and fresh. The
+// merged view keeps serving the others while some registries are degraded.
+func (st FederationStatus) Ready() bool {
+	return slices.ContainsFunc(st.Registries, Status.Ready)
+}
+
+// Status returns a point-in-time view of all registries (thread-safe).
+func (f *Federation) Status() FederationStatus {
+	st := FederationStatus{
+		ConflictPolicy: f.cfg.ConflictPolicy,
+		Registries:     make([]Status, 0, len(f.syncers)),
+	}
+	for _, s := range f.syncers {
+		rs := s.Status()
+		st.Registries = append(st.Registries, rs)
+		if !rs.Ready() {
+			st.Degraded = append(st.Degraded, rs.Name)
+		}
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.snapshot != nil {
+		st.ClaimCount = f.snapshot.Len()
+	}
+	st.Conflicts = f.conflicts
+	return st
+}
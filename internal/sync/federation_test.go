@@ -0,0 +1,268 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+const otherRegistryYAML = `
+apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: hacky
+    template: harvestervm
+    category: infra
+    status: active
+  - name: vsphere-vm
+    template: vspherevm
+    category: infra
+    status: active
+`
+
+func newTestFederation(t *testing.T, policy ConflictPolicy, bodies ...string) *Federation {
+	t.Helper()
+
+	syncers := make([]*Syncer, 0, len(bodies))
+	for i, body := range bodies {
+		ts := newTestServer(t, body, http.StatusOK)
+		t.Cleanup(ts.Close)
+		syncers = append(syncers, NewSyncer(Config{
+			Name:    []string{"harvester", "vsphere", "harbor"}[i],
+			Repo:    "test/repo",
+			BaseURL: ts.URL,
+		}))
+	}
+
+	return NewFederation(FederationConfig{ConflictPolicy: policy}, syncers...)
+}
+
+func TestFederationMerge(t *testing.T) {
+	f := newTestFederation(t, "", testRegistryYAML, otherRegistryYAML)
+	require.NoError(t, f.InitialSync(context.Background()))
+
+	reg := f.GetRegistry()
+	require.NotNil(t, reg)
+	require.Len(t, reg.Claims, 3)
+
+	assert.Equal(t, "hacky", reg.Claims[0].Name)
+	assert.Equal(t, "volumeclaim", reg.Claims[0].Template, "first registry wins by default")
+	assert.Equal(t, "harvester", reg.Claims[0].Registry)
+	assert.Equal(t, "demo", reg.Claims[1].Name)
+	assert.Equal(t, "harvester", reg.Claims[1].Registry)
+	assert.Equal(t, "vsphere-vm", reg.Claims[2].Name)
+	assert.Equal(t, "vsphere", reg.Claims[2].Registry)
+
+	require.Len(t, f.Conflicts(), 1)
+	assert.Equal(t, Conflict{Name: "hacky", Registries: []string{"harvester", "vsphere"}, Winner: "harvester"}, f.Conflicts()[0])
+
+	// Member snapshots are not annotated in place
+	assert.Empty(t, f.Syncers()[0].GetRegistry().Claims[0].Registry)
+}
+
+func TestFederationConflictPolicies(t *testing.T) {
+	f := newTestFederation(t, ConflictLast, testRegistryYAML, otherRegistryYAML)
+	require.NoError(t, f.InitialSync(context.Background()))
+
+	entry := registry.FindEntry(f.GetRegistry(), "hacky")
+	require.NotNil(t, entry)
+	assert.Equal(t, "harvestervm", entry.Template)
+	assert.Equal(t, "vsphere", entry.Registry)
+
+	f = newTestFederation(t, ConflictReject, testRegistryYAML, otherRegistryYAML)
+	require.NoError(t, f.InitialSync(context.Background()))
+
+	assert.Nil(t, registry.FindEntry(f.GetRegistry(), "hacky"))
+	assert.Len(t, f.GetRegistry().Claims, 2)
+	assert.Empty(t, f.Conflicts()[0].Winner)
+}
+
+func TestFederationPartialInitialSync(t *testing.T) {
+	ok := newTestServer(t, testRegistryYAML, http.StatusOK)
+	defer ok.Close()
+	broken := newTestServer(t, "boom", http.StatusInternalServerError)
+	defer broken.Close()
+
+	f := NewFederation(FederationConfig{},
+		NewSyncer(Config{Name: "ok", Repo: "test/ok", BaseURL: ok.URL}),
+		NewSyncer(Config{Name: "broken", Repo: "test/broken", BaseURL: broken.URL}),
+	)
+
+	require.NoError(t, f.InitialSync(context.Background()))
+	assert.Len(t, f.GetRegistry().Claims, 2)
+
+	st := f.Status()
+	assert.Equal(t, 2, st.ClaimCount)
+	require.Len(t, st.Registries, 2)
+	assert.True(t, st.Registries[0].Ready())
+	assert.False(t, st.Registries[1].Ready())
+	assert.True(t, st.Ready(), "one loaded registry keeps the federation ready")
+	assert.Equal(t, []string{"broken"}, st.Degraded)
+}
+
+func TestFederationAllFail(t *testing.T) {
+	ts := newTestServer(t, "boom", http.StatusInternalServerError)
+	defer ts.Close()
+
+	f := NewFederation(FederationConfig{}, NewSyncer(Config{Repo: "test/repo", BaseURL: ts.URL}))
+
+	assert.Error(t, f.InitialSync(context.Background()))
+	assert.Nil(t, f.GetRegistry())
+}
+
+func TestFederationRemergesOnMemberSwap(t *testing.T) {
+	body := otherRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	first := newTestServer(t, testRegistryYAML, http.StatusOK)
+	defer first.Close()
+
+	vsphere := NewSyncer(Config{Name: "vsphere", Repo: "test/vsphere", BaseURL: ts.URL})
+	f := NewFederation(FederationConfig{},
+		NewSyncer(Config{Name: "harvester", Repo: "test/harvester", BaseURL: first.URL}),
+		vsphere,
+	)
+	require.NoError(t, f.InitialSync(context.Background()))
+	assert.Len(t, f.GetRegistry().Claims, 3)
+	before := f.Snapshot()
+	assert.Len(t, before.Search("vsphere", 0), 1)
+
+	body = testRegistryYAML
+	vsphere.sync(context.Background())
+
+	assert.Len(t, f.GetRegistry().Claims, 2)
+	assert.Len(t, f.Conflicts(), 2)
+	after := f.Snapshot()
+	assert.Empty(t, after.Search("vsphere", 0), "index is rebuilt on swap")
+	assert.NotEqual(t, before.Version(), after.Version())
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	p, err := ParseConflictPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictFirst, p)
+
+	p, err = ParseConflictPolicy("reject")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictReject, p)
+
+	_, err = ParseConflictPolicy("merge")
+	assert.Error(t, err)
+}
+
+func TestFederationEvents(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{Name: "harvester", Repo: "test/repo", BaseURL: ts.URL})
+	f := NewFederation(FederationConfig{}, s)
+	require.NoError(t, f.InitialSync(context.Background()))
+
+	snap, rv := f.Current()
+	require.NotNil(t, snap)
+	events, _, err := f.Events().Since(rv)
+	require.NoError(t, err)
+	assert.Empty(t, events, "initial load is not reported as changes")
+
+	body = otherRegistryYAML
+	s.sync(context.Background())
+
+	events, _, err = f.Events().Since(rv)
+	require.NoError(t, err)
+	var got []string
+	for _, ev := range events {
+		got = append(got, string(ev.Type)+" "+ev.Object.Name)
+	}
+	assert.Equal(t, []string{"MODIFIED hacky", "ADDED vsphere-vm", "DELETED demo"}, got)
+	assert.Equal(t, "harvestervm", events[0].Object.Template)
+	assert.Equal(t, "harvester", events[0].Object.Registry)
+
+	_, current := f.Current()
+	assert.Equal(t, events[2].ResourceVersion, current)
+}
+
+func TestFederationHistory(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{Name: "harvester", Repo: "test/repo", BaseURL: ts.URL})
+	f := NewFederation(FederationConfig{}, s)
+	require.NoError(t, f.InitialSync(context.Background()))
+	initial := s.Revision()
+	assert.Empty(t, f.History().Since(time.Time{}), "initial load is not a changeset")
+
+	body = otherRegistryYAML
+	s.sync(context.Background())
+
+	sets := f.History().Since(time.Time{})
+	require.Len(t, sets, 1)
+	assert.Equal(t, "harvester", sets[0].Registry)
+	assert.Equal(t, initial, sets[0].PreviousRevision)
+	assert.Equal(t, s.Revision(), sets[0].Revision)
+	assert.Len(t, sets[0].Changes, 3)
+
+	// Unchanged syncs are not recorded.
+	s.sync(context.Background())
+	assert.Len(t, f.History().Since(time.Time{}), 1)
+
+	hacky := f.History().Claim("hacky")
+	require.Len(t, hacky, 1)
+	assert.Equal(t, registry.Modified, hacky[0].Type)
+	assert.Equal(t, []registry.FieldChange{
+		{Field: "category", Old: "cli", New: "infra"},
+		{Field: "template", Old: "volumeclaim", New: "harvestervm"},
+	}, hacky[0].Fields)
+}
+
+func TestFederationOnChange(t *testing.T) {
+	bodies := []string{testRegistryYAML, otherRegistryYAML}
+	var syncers []*Syncer
+	for i, name := range []string{"harvester", "vsphere"} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(bodies[i]))
+		}))
+		t.Cleanup(ts.Close)
+		syncers = append(syncers, NewSyncer(Config{Name: name, Repo: "test/repo", BaseURL: ts.URL}))
+	}
+
+	var notified []Changeset
+	f := NewFederation(FederationConfig{OnChange: func(cs Changeset) { notified = append(notified, cs) }}, syncers...)
+	require.NoError(t, f.InitialSync(context.Background()))
+	assert.Empty(t, notified, "initial loads are not reported")
+
+	// hacky is shadowed by the harvester registry, so only the change to
+	// vsphere-vm reaches the merged view.
+	bodies[1] = strings.ReplaceAll(otherRegistryYAML, "status: active", "status: deleted")
+	syncers[1].sync(context.Background())
+
+	require.Len(t, notified, 1)
+	cs := notified[0]
+	assert.Equal(t, "vsphere", cs.Registry)
+	assert.Equal(t, syncers[1].Revision(), cs.Revision)
+	require.Len(t, cs.Changes, 1)
+	assert.Equal(t, registry.Modified, cs.Changes[0].Type)
+	assert.Equal(t, "vsphere-vm", cs.Changes[0].Claim.Name)
+	assert.Equal(t, "vsphere", cs.Changes[0].Claim.Registry)
+
+	// The member history still records both changes.
+	sets := f.History().Since(time.Time{})
+	require.Len(t, sets, 1)
+	assert.Len(t, sets[0].Changes, 2)
+}
@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSourceFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testRegistryYAML), 0o644))
+
+	s := NewSyncer(Config{Source: NewFileSource(path)})
+
+	err := s.InitialSync(context.Background())
+	require.NoError(t, err)
+
+	reg := s.GetRegistry()
+	require.NotNil(t, reg)
+	assert.Len(t, reg.Claims, 2)
+
+	// The revision is abbreviated for display; unchanged content is
+	// detected by the full digest.
+	_, hash := s.snapshot()
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash[:12], s.Revision())
+}
+
+func TestFileSourceMissing(t *testing.T) {
+	s := NewSyncer(Config{Source: NewFileSource(filepath.Join(t.TempDir(), "missing.yaml"))})
+
+	err := s.InitialSync(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, s.GetRegistry())
+}
+
+func TestFileSourceWatchReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testRegistryYAML), 0o644))
+
+	s := NewSyncer(Config{
+		Source:   NewFileSource(path),
+		Interval: time.Hour,
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	s.Start(context.Background())
+	defer s.Stop()
+
+	updated := testRegistryYAML + `  - name: added
+    template: volumeclaim
+    category: cli
+    status: active
+`
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0o644))
+
+	assert.Eventually(t, func() bool {
+		return len(s.GetRegistry().Claims) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+}
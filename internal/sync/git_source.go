@@ -0,0 +1,206 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// GitSource maintains a shallow bare clone of a git remote and reads the
+// registry file from the tip of the configured branch. Unlike GitHubSource
+// it works with any git remote (https, SSH, file://) and is not subject to
+// CDN caching.
+type GitSource struct {
+	URL    string // Git remote URL
+	Branch string // Git branch
+	Path   string // Path to registry file in repo
+	Token  string // Token for HTTP(S) remotes (optional)
+	Dir    string // Clone directory; a temp dir is created when empty
+
+	// Timeout bounds each fetch from the remote, including the first one
+	// that fills an empty clone; zero means no limit.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	repo     *git.Repository
+	revision string
+	tempDir  bool // Dir was created by open and is removed by Close
+}
+
+// NewGitSource creates a GitSource from the syncer configuration. Repo may be
+// a full remote URL or a GitHub slug such as "stuttgart-things/harvester".
+func NewGitSource(cfg Config) *GitSource {
+	branch := cfg.Branch
+	if branch == "" {
+		branch = "main"
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "claims/registry.yaml"
+	}
+	return &GitSource{
+		URL:     gitRemoteURL(cfg.Repo),
+		Branch:  branch,
+		Path:    path,
+		Token:   cfg.Token,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// gitRemoteURL expands GitHub slugs into clone URLs and leaves anything that
+// already looks like a remote (scheme, scp-style or local path) untouched.
+func gitRemoteURL(repo string) string {
+	if strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@") || filepath.IsAbs(repo) {
+		return repo
+	}
+	return "https://github.com/" + strings.TrimSuffix(repo, ".git") + ".git"
+}
+
+// String implements Source.
+func (g *GitSource) String() string {
+	return fmt.Sprintf("%s@%s:%s", g.URL, g.Branch, g.Path)
+}
+
+// Fetch implements Source. The first call initializes the clone; subsequent
+// calls fetch the branch tip with depth 1 and return ErrNotModified while the
+// tip commit is the last committed revision.
+func (g *GitSource) Fetch(ctx context.Context) (*FetchResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.repo == nil {
+		repo, err := g.open()
+		if err != nil {
+			return nil, err
+		}
+		g.repo = repo
+	}
+
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.Timeout)
+		defer cancel()
+	}
+
+	refName := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, g.Branch)
+	err := g.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(g.Branch), refName)),
+		},
+		Depth: 1,
+		Auth:  g.auth(),
+		Force: true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("fetching %s: %w", g.URL, err)
+	}
+
+	ref, err := g.repo.Reference(refName, true)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", refName, err)
+	}
+
+	if ref.Hash().String() == g.revision {
+		return nil, ErrNotModified
+	}
+
+	commit, err := g.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", ref.Hash(), err)
+	}
+
+	file, err := commit.File(g.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", g.Path, ref.Hash(), err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", g.Path, ref.Hash(), err)
+	}
+
+	return &FetchResult{Data: []byte(contents), Revision: ref.Hash().String(), Time: time.Now()}, nil
+}
+
+// Commit implements Committer by reporting later fetches of the same tip
+// commit as not modified.
+func (g *GitSource) Commit(res *FetchResult) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.revision = res.Revision
+}
+
+// open opens an existing clone in Dir or initializes a new bare repository
+// with the configured remote.
+func (g *GitSource) open() (*git.Repository, error) {
+	if g.Dir == "" {
+		dir, err := os.MkdirTemp("", "machinery-registry-git-")
+		if err != nil {
+			return nil, fmt.Errorf("creating clone directory: %w", err)
+		}
+		g.Dir = dir
+		g.tempDir = true
+	}
+
+	repo, err := git.PlainOpen(g.Dir)
+	if err == nil {
+		return repo, nil
+	}
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, fmt.Errorf("opening clone in %s: %w", g.Dir, err)
+	}
+
+	repo, err = git.PlainInit(g.Dir, true)
+	if err != nil {
+		return nil, fmt.Errorf("initializing clone in %s: %w", g.Dir, err)
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{g.URL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configuring remote %s: %w", g.URL, err)
+	}
+
+	return repo, nil
+}
+
+// Close implements io.Closer. It removes the clone directory if it was
+// created as a temp dir; a configured Dir is kept for the next start.
+func (g *GitSource) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.repo = nil
+	if !g.tempDir {
+		return nil
+	}
+	dir := g.Dir
+	g.Dir, g.tempDir, g.revision = "", false, ""
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing clone directory: %w", err)
+	}
+	return nil
+}
+
+// auth returns token credentials for HTTP(S) remotes. SSH remotes fall back
+// to go-git's default (ssh-agent) authentication.
+func (g *GitSource) auth() transport.AuthMethod {
+	if g.Token == "" || !strings.HasPrefix(g.URL, "http") {
+		return nil
+	}
+	return &githttp.BasicAuth{Username: "x-access-token", Password: g.Token}
+}
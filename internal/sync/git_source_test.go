@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo creates a local git repository on branch main and returns its
+// directory together with a helper that commits registry contents.
+func newTestRepo(t *testing.T) (string, func(content string) string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	commit := func(content string) string {
+		t.Helper()
+		path := filepath.Join(dir, "claims", "registry.yaml")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		_, err := wt.Add("claims/registry.yaml")
+		require.NoError(t, err)
+		hash, err := wt.Commit("update registry", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	return dir, commit
+}
+
+func TestGitSourceInitialSync(t *testing.T) {
+	dir, commit := newTestRepo(t)
+	sha := commit(testRegistryYAML)
+
+	src := NewGitSource(Config{Repo: "file://" + dir})
+	s := NewSyncer(Config{Source: src})
+
+	err := s.InitialSync(context.Background())
+	require.NoError(t, err)
+
+	reg := s.GetRegistry()
+	require.NotNil(t, reg)
+	assert.Len(t, reg.Claims, 2)
+	assert.Equal(t, sha, s.Revision())
+
+	// The temporary clone is removed on Stop.
+	clone := src.Dir
+	require.DirExists(t, clone)
+	s.Stop()
+	assert.NoDirExists(t, clone)
+}
+
+func TestGitSourcePullsNewCommits(t *testing.T) {
+	dir, commit := newTestRepo(t)
+	commit(testRegistryYAML)
+
+	src := NewGitSource(Config{Repo: "file://" + dir})
+	src.Dir = t.TempDir()
+	defer func() {
+		require.NoError(t, src.Close())
+		assert.DirExists(t, src.Dir, "a configured clone directory is kept")
+	}()
+
+	res, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testRegistryYAML, string(res.Data))
+
+	updated := testRegistryYAML + `  - name: added
+    template: volumeclaim
+    category: cli
+    status: active
+`
+	sha := commit(updated)
+
+	res, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, updated, string(res.Data))
+	assert.Equal(t, sha, res.Revision)
+
+	// Uncommitted revisions are fetched again.
+	res, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, sha, res.Revision)
+
+	src.Commit(res)
+	_, err = src.Fetch(context.Background())
+	assert.ErrorIs(t, err, ErrNotModified)
+}
+
+func TestGitSourceMissingBranch(t *testing.T) {
+	dir, commit := newTestRepo(t)
+	commit(testRegistryYAML)
+
+	src := NewGitSource(Config{Repo: "file://" + dir, Branch: "nope"})
+	src.Dir = t.TempDir()
+
+	_, err := src.Fetch(context.Background())
+	assert.Error(t, err)
+}
+
+func TestGitSourceFetchTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stop
+	}))
+	defer ts.Close()
+	defer close(stop)
+
+	src := NewGitSource(Config{Repo: ts.URL + "/test/repo.git"})
+	assert.Equal(t, 30*time.Second, src.Timeout)
+	src.Dir = t.TempDir()
+	src.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := src.Fetch(context.Background())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestGitRemoteURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/stuttgart-things/harvester.git", gitRemoteURL("stuttgart-things/harvester"))
+	assert.Equal(t, "git@github.com:stuttgart-things/harvester.git", gitRemoteURL("git@github.com:stuttgart-things/harvester.git"))
+	assert.Equal(t, "file:///tmp/repo", gitRemoteURL("file:///tmp/repo"))
+	assert.Equal(t, "/tmp/repo", gitRemoteURL("/tmp/repo"))
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// DefaultHistorySize is the number of changesets kept by a History.
+const DefaultHistorySize = 100
+
+// Changeset is the set of claim changes one sync applied to a registry.
+type Changeset struct {
+	Registry         string            `json:"registry"`
+	Revision         string            `json:"revision"`
+	PreviousRevision string            `json:"previousRevision"`
+	Time             time.Time         `json:"time"`
+	Changes          []registry.Change `json:"changes"`
+}
+
+// ClaimChange is a change to one claim together with the sync it came from.
+type ClaimChange struct {
+	Registry string    `json:"registry"`
+	Revision string    `json:"revision"`
+	Time     time.Time `json:"time"`
+	registry.Change
+}
+
+// History keeps the most recent changesets of all registries in the order
+// they were applied. It is safe for concurrent use.
+type History struct {
+	mu   sync.RWMutex
+	size int
+	sets []Changeset // oldest first
+}
+
+// NewHistory creates a History retaining up to size changesets.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size}
+}
+
+// Add records cs, evicting the oldest changeset once the history is full.
+func (h *History) Add(cs Changeset) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sets = append(h.sets, cs)
+	if len(h.sets) > h.size {
+		h.sets = slices.Delete(h.sets, 0, len(h.sets)-h.size)
+	}
+}
+
+// Since returns the retained changesets applied after t, oldest first.
+func (h *History) Since(t time.Time) []Changeset {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var sets []Changeset
+	for _, cs := range h.sets {
+		if cs.Time.After(t) {
+			sets = append(sets, cs)
+		}
+	}
+	return sets
+}
+
+// After returns the retained changesets applied after revision, oldest
+// first: those following the most recent changeset that produced revision,
+// or else from the first changeset that replaced it. The second result is
+// false when revision is unknown to the history.
+func (h *History) After(revision string) ([]Changeset, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for i := len(h.sets) - 1; i >= 0; i-- {
+		if h.sets[i].Revision == revision {
+			return slices.Clone(h.sets[i+1:]), true
+		}
+	}
+	for i, cs := range h.sets {
+		if cs.PreviousRevision == revision {
+			return slices.Clone(h.sets[i:]), true
+		}
+	}
+	return nil, false
+}
+
+// Claim returns the retained changes to the named claim, newest first.
+func (h *History) Claim(name string) []ClaimChange {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var changes []ClaimChange
+	for i := len(h.sets) - 1; i >= 0; i-- {
+		cs := &h.sets[i]
+		for _, c := range cs.Changes {
+			if c.Claim.Name == name {
+				changes = append(changes, ClaimChange{
+					Registry: cs.Registry,
+					Revision: cs.Revision,
+					Time:     cs.Time,
+					Change:   c,
+				})
+			}
+		}
+	}
+	return changes
+}
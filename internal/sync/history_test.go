@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+func changeset(reg, prev, rev string, at time.Time, changes ...registry.Change) Changeset {
+	return Changeset{Registry: reg, PreviousRevision: prev, Revision: rev, Time: at, Changes: changes}
+}
+
+func revisions(sets []Changeset) []string {
+	revs := make([]string, len(sets))
+	for i, cs := range sets {
+		revs[i] = cs.Revision
+	}
+	return revs
+}
+
+func TestHistory(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHistory(3)
+	h.Add(changeset("a", "r0", "r1", t0, added("x")...))
+	h.Add(changeset("b", "s0", "s1", t0.Add(time.Minute), added("y")...))
+	h.Add(changeset("a", "r1", "r2", t0.Add(2*time.Minute), registry.Change{
+		Type:   registry.Modified,
+		Claim:  registry.ClaimEntry{Name: "x", Status: "inactive"},
+		Fields: []registry.FieldChange{{Field: "status", Old: "active", New: "inactive"}},
+	}))
+
+	assert.Equal(t, []string{"r1", "s1", "r2"}, revisions(h.Since(time.Time{})))
+	assert.Equal(t, []string{"s1", "r2"}, revisions(h.Since(t0)))
+
+	after, ok := h.After("r1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"s1", "r2"}, revisions(after))
+	after, ok = h.After("r0")
+	assert.True(t, ok, "revision replaced by a retained changeset")
+	assert.Equal(t, []string{"r1", "s1", "r2"}, revisions(after))
+	_, ok = h.After("unknown")
+	assert.False(t, ok)
+
+	claim := h.Claim("x")
+	if assert.Len(t, claim, 2) {
+		assert.Equal(t, "r2", claim[0].Revision, "newest first")
+		assert.Equal(t, registry.Modified, claim[0].Type)
+		assert.Equal(t, "status", claim[0].Fields[0].Field)
+		assert.Equal(t, registry.Added, claim[1].Type)
+	}
+
+	h.Add(changeset("b", "s1", "s2", t0.Add(3*time.Minute)))
+	assert.Equal(t, []string{"s1", "r2", "s2"}, revisions(h.Since(time.Time{})), "oldest evicted")
+	assert.Len(t, h.Claim("x"), 1)
+}
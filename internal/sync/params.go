@@ -0,0 +1,228 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// ParamCrawlerConfig holds parameter crawler configuration.
+type ParamCrawlerConfig struct {
+	Fetcher     *manifest.Fetcher // Reads the claim manifests
+	Interval    time.Duration     // Time between full crawls; defaults to 10m
+	Concurrency int               // Parallel manifest fetches; defaults to 8
+	Logger      *slog.Logger      // Defaults to slog.Default()
+}
+
+// ParamCrawler fetches the manifests referenced by the served claims in the
+// background and extracts their spec.parameters. A Federation attaches the
+// extracted parameters to its merged entries and re-merges whenever they
+// change.
+type ParamCrawler struct {
+	cfg      ParamCrawlerConfig
+	mu       sync.RWMutex
+	params   map[paramRef]map[string]any
+	hash     string // content hash of params
+	trigger  chan struct{}
+	onChange func()
+	refs     func() []paramRef // references to crawl
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// paramRef identifies a claim manifest.
+type paramRef struct {
+	repository, path string
+}
+
+// NewParamCrawler creates a ParamCrawler with the given configuration.
+func NewParamCrawler(cfg ParamCrawlerConfig) *ParamCrawler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 8
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &ParamCrawler{
+		cfg:     cfg,
+		params:  map[paramRef]map[string]any{},
+		hash:    contentHash(nil),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Params returns the parameters extracted from the manifest of e, or nil.
+func (c *ParamCrawler) Params(e *registry.ClaimEntry) map[string]any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.params[paramRef{e.Repository, e.Path}]
+}
+
+// Hash returns a content hash of all extracted parameters.
+func (c *ParamCrawler) Hash() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hash
+}
+
+// Trigger requests a crawl; requests made while one is pending coalesce.
+func (c *ParamCrawler) Trigger() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// start begins the crawl loop. It crawls immediately, every interval and
+// whenever triggered.
+func (c *ParamCrawler) start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(c.done)
+		for {
+			c.crawl(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.cfg.Interval):
+			case <-c.trigger:
+			}
+		}
+	}()
+}
+
+// stop terminates the crawl loop and waits for it to finish.
+func (c *ParamCrawler) stop() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+}
+
+// crawl fetches every referenced manifest and, if the extracted parameters
+// changed, replaces them and calls onChange. Manifests that cannot be
+// fetched keep their previous parameters; deleted ones lose them.
+func (c *ParamCrawler) crawl(ctx context.Context) {
+	refs := c.refs()
+
+	c.mu.RLock()
+	prev := c.params
+	c.mu.RUnlock()
+
+	var (
+		mu     sync.Mutex
+		next   = make(map[paramRef]map[string]any, len(refs))
+		failed int
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, c.cfg.Concurrency)
+	)
+	for _, ref := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+
+			params, err := c.extract(ctx, ref)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				if params != nil {
+					next[ref] = params
+				}
+			case errors.Is(err, manifest.ErrNotFound), errors.Is(err, manifest.ErrInvalidReference):
+			default:
+				failed++
+				if old, ok := prev[ref]; ok {
+					next[ref] = old
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	if failed > 0 {
+		c.cfg.Logger.Warn("parameter crawl incomplete", "manifests", len(refs), "failed", failed)
+	}
+	if reflect.DeepEqual(prev, next) {
+		return
+	}
+
+	// json.Marshal sorts map keys, so equal parameters hash equally.
+	byRef := make(map[string]map[string]any, len(next))
+	for ref, p := range next {
+		byRef[ref.repository+"\x00"+ref.path] = p
+	}
+	data, err := json.Marshal(byRef)
+	if err != nil {
+		c.cfg.Logger.Error("hashing parameters failed", logging.Err(err))
+		return
+	}
+
+	c.mu.Lock()
+	c.params = next
+	c.hash = contentHash(data)
+	c.mu.Unlock()
+
+	c.cfg.Logger.Info("claim parameters updated", "manifests", len(refs), "withParameters", len(next))
+	if c.onChange != nil {
+		c.onChange()
+	}
+}
+
+// extract fetches the manifest at ref and returns its spec.parameters, or
+// nil if it has none. In multi-document files the first document with
+// parameters counts.
+func (c *ParamCrawler) extract(ctx context.Context, ref paramRef) (map[string]any, error) {
+	m, err := c.cfg.Fetcher.Get(ctx, ref.repository, ref.path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := m.Parse()
+	if err != nil {
+		return nil, err
+	}
+	docs, ok := v.([]any)
+	if !ok {
+		docs = []any{v}
+	}
+	for _, doc := range docs {
+		obj, _ := doc.(map[string]any)
+		spec, _ := obj["spec"].(map[string]any)
+		if params, ok := spec["parameters"].(map[string]any); ok {
+			return maps.Clone(params), nil
+		}
+	}
+	return nil, nil
+}
+
+// claimRefs returns the distinct manifest references of entries.
+func claimRefs(entries []registry.ClaimEntry) []paramRef {
+	seen := map[paramRef]bool{}
+	var refs []paramRef
+	for i := range entries {
+		ref := paramRef{entries[i].Repository, entries[i].Path}
+		if ref.repository == "" || ref.path == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
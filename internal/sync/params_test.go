@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/manifest"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+const paramRegistryYAML = `
+apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+  - name: vm
+    template: harvestervm
+    category: infra
+    status: active
+    repository: stuttgart-things/harvester
+    path: claims/vm.yaml
+  - name: vm-copy
+    template: harvestervm
+    category: infra
+    status: active
+    repository: stuttgart-things/harvester
+    path: claims/vm.yaml
+  - name: bucket
+    template: bucket
+    category: infra
+    status: active
+    repository: stuttgart-things/harvester
+    path: claims/bucket.yaml
+`
+
+// manifestServer serves claim manifests from a fake GitHub whose responses
+// can be changed by the test.
+type manifestServer struct {
+	mu     gosync.Mutex
+	status int
+	files  map[string]string
+}
+
+func (m *manifestServer) set(status int, files map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status, m.files = status, files
+}
+
+func newParamFederation(t *testing.T) (*Federation, *manifestServer) {
+	t.Helper()
+	ms := &manifestServer{status: http.StatusOK}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms.mu.Lock()
+		defer ms.mu.Unlock()
+		body, ok := ms.files[r.URL.Path]
+		switch {
+		case ms.status != http.StatusOK:
+			w.WriteHeader(ms.status)
+		case !ok:
+			http.NotFound(w, r)
+		default:
+			w.Write([]byte(body))
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	ts := newTestServer(t, paramRegistryYAML, http.StatusOK)
+	t.Cleanup(ts.Close)
+
+	crawler := NewParamCrawler(ParamCrawlerConfig{
+		Fetcher: manifest.NewFetcher(manifest.Config{BaseURL: upstream.URL, TTL: time.Nanosecond}),
+	})
+	f := NewFederation(FederationConfig{Crawler: crawler},
+		NewSyncer(Config{Name: "harvester", Repo: "test/repo", BaseURL: ts.URL}))
+	require.NoError(t, f.InitialSync(context.Background()))
+	return f, ms
+}
+
+func TestParamCrawler(t *testing.T) {
+	f, ms := newParamFederation(t)
+	crawler := f.cfg.Crawler
+	ctx := context.Background()
+	vmPath := "/stuttgart-things/harvester/HEAD/claims/vm.yaml"
+
+	before := f.Snapshot().Version()
+	ms.set(http.StatusOK, map[string]string{
+		vmPath: `spec: {parameters: {cpu: 2, disk: {size: 20Gi}}}`,
+		"/stuttgart-things/harvester/HEAD/claims/bucket.yaml": "kind: Bucket\nspec: {size: 1Gi}\n",
+	})
+	crawler.crawl(ctx)
+
+	snap := f.Snapshot()
+	assert.NotEqual(t, before, snap.Version(), "parameters change the version")
+	for _, name := range []string{"vm", "vm-copy"} {
+		e, ok := snap.Get(name)
+		require.True(t, ok)
+		v, _ := e.Field("param.cpu")
+		assert.Equal(t, "2", v, name)
+		v, _ = e.Field("param.disk.size")
+		assert.Equal(t, "20Gi", v, name)
+	}
+	bucket, _ := snap.Get("bucket")
+	assert.Nil(t, bucket.Parameters, "manifests without spec.parameters")
+
+	// Unchanged parameters keep the merged view.
+	crawler.crawl(ctx)
+	assert.Same(t, snap, f.Snapshot())
+
+	// Changed parameters are re-merged and reported as modifications.
+	_, rv := f.Current()
+	ms.set(http.StatusOK, map[string]string{vmPath: "spec: {parameters: {cpu: 4}}"})
+	crawler.crawl(ctx)
+	e, _ := f.Snapshot().Get("vm")
+	v, _ := e.Field("param.cpu")
+	assert.Equal(t, "4", v)
+	events, _, err := f.Events().Since(rv)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, registry.Modified, events[0].Type)
+	assert.Equal(t, "vm", events[0].Object.Name)
+
+	// Failed fetches keep the last parameters; missing manifests drop them.
+	ms.set(http.StatusBadGateway, nil)
+	crawler.crawl(ctx)
+	e, _ = f.Snapshot().Get("vm")
+	assert.NotNil(t, e.Parameters)
+
+	ms.set(http.StatusOK, nil)
+	crawler.crawl(ctx)
+	e, _ = f.Snapshot().Get("vm")
+	assert.Nil(t, e.Parameters)
+}
+
+func TestParamCrawlerStartStop(t *testing.T) {
+	f, ms := newParamFederation(t)
+	ms.set(http.StatusOK, map[string]string{
+		"/stuttgart-things/harvester/HEAD/claims/vm.yaml": "spec: {parameters: {cpu: 8}}",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.Start(ctx)
+	defer f.Stop()
+
+	assert.Eventually(t, func() bool {
+		e, _ := f.Snapshot().Get("vm")
+		v, _ := e.Field("param.cpu")
+		return v == "8"
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,156 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// ErrNotModified is returned by Source.Fetch when the source reports that
+// the registry has not changed since the previous fetch.
+var ErrNotModified = errors.New("registry not modified")
+
+// Source fetches the raw registry file from a backing store.
+type Source interface {
+	// Fetch returns the current registry file contents.
+	Fetch(ctx context.Context) (*FetchResult, error)
+	// String describes the source for log output.
+	String() string
+}
+
+// Watcher is implemented by sources that can signal changes without polling.
+// Each receive on the returned channel requests an immediate re-fetch.
+type Watcher interface {
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+// Committer is implemented by sources whose fetches are conditional on an
+// earlier result. The syncer calls Commit once it has accepted res, so that
+// a rejected registry is fetched in full again instead of being reported as
+// not modified.
+type Committer interface {
+	Commit(res *FetchResult)
+}
+
+// FetchResult holds the raw registry bytes and metadata about their origin.
+type FetchResult struct {
+	Data     []byte    // Raw registry file contents
+	Revision string    // Source-specific revision (ETag, content hash, ...)
+	Time     time.Time // When the data was fetched
+
+	etag         string // Validators for conditional requests, see Committer
+	lastModified string
+}
+
+// GitHubSource fetches the registry file via raw.githubusercontent.com.
+type GitHubSource struct {
+	BaseURL string // Raw content base URL
+	Repo    string // GitHub repo slug, e.g. "stuttgart-things/harvester"
+	Branch  string // Git branch
+	Path    string // Path to registry file in repo
+	Token   string // GitHub token (optional, for private repos)
+
+	client       *http.Client
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+}
+
+// NewGitHubSource creates a GitHubSource from the syncer configuration.
+func NewGitHubSource(cfg Config) *GitHubSource {
+	return &GitHubSource{
+		BaseURL: cfg.BaseURL,
+		Repo:    cfg.Repo,
+		Branch:  cfg.Branch,
+		Path:    cfg.Path,
+		Token:   cfg.Token,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+// rawURL returns the GitHub raw content URL for the registry file.
+func (g *GitHubSource) rawURL() string {
+	return fmt.Sprintf("%s/%s/%s/%s", g.BaseURL, g.Repo, g.Branch, g.Path)
+}
+
+// String implements Source.
+func (g *GitHubSource) String() string {
+	return g.rawURL()
+}
+
+// Fetch implements Source. Requests are conditional on the ETag and
+// Last-Modified of the last committed response; a 304 yields ErrNotModified.
+func (g *GitHubSource) Fetch(ctx context.Context) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.rawURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	if g.Token != "" {
+		req.Header.Set("Authorization", "token "+g.Token)
+	}
+
+	g.mu.Lock()
+	if g.etag != "" {
+		req.Header.Set("If-None-Match", g.etag)
+	}
+	if g.lastModified != "" {
+		req.Header.Set("If-Modified-Since", g.lastModified)
+	}
+	g.mu.Unlock()
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		se := &StatusError{StatusCode: resp.StatusCode, URL: g.rawURL()}
+		switch resp.StatusCode {
+		case http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			se.RetryAfter = retryAfter(resp.Header, time.Now())
+		}
+		return nil, se
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	revision := resp.Header.Get("ETag")
+	if revision == "" {
+		revision = shortHash(contentHash(data))
+	}
+
+	return &FetchResult{
+		Data:         data,
+		Revision:     revision,
+		Time:         time.Now(),
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// Commit implements Committer by making later requests conditional on the
+// validators of res.
+func (g *GitHubSource) Commit(res *FetchResult) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.etag = res.etag
+	g.lastModified = res.lastModified
+}
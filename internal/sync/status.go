@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+)
+
+// Syncer states reported in Status.
+const (
+	StatePending = "pending"            // no snapshot loaded yet
+	StateLive    = "live"               // serving a snapshot fetched by this process
+	StateCached  = "serving-from-cache" // serving the persisted snapshot until a live sync succeeds
+)
+
+// Status describes the state of a Syncer for diagnostics and readiness.
+type Status struct {
+	Name                string    `json:"name"`
+	Source              string    `json:"source"`
+	State               string    `json:"state"`
+	Revision            string    `json:"revision,omitempty"`
+	ClaimCount          int       `json:"claimCount"`
+	LastAttempt         time.Time `json:"lastAttempt,omitzero"`
+	LastSuccess         time.Time `json:"lastSuccess,omitzero"`
+	LastChanged         time.Time `json:"lastChanged,omitzero"`
+	NextAttempt         time.Time `json:"nextAttempt,omitzero"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+	StaleAfter          string    `json:"staleAfter"`
+	Stale               bool      `json:"stale"`
+
+	// Violations lists validation findings for the most recently fetched
+	// registry content; errors mean that content was rejected.
+	Violations registry.Violations `json:"violations,omitempty"`
+}
+
+// Ready reports whether a snapshot is loaded and not stale. A snapshot
+// served from cache counts as ready, so that a restart during an upstream
+// outage keeps serving.
+func (st Status) Ready() bool {
+	return st.State == StateCached || (!st.LastSuccess.IsZero() && !st.Stale)
+}
+
+// Status returns a point-in-time view of the syncer state (thread-safe).
+func (s *Syncer) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := Status{
+		Name:                s.cfg.Name,
+		Source:              s.cfg.Source.String(),
+		Revision:            s.revision,
+		LastAttempt:         s.lastAttempt,
+		LastSuccess:         s.lastChecked,
+		LastChanged:         s.lastChanged,
+		NextAttempt:         s.nextAttempt,
+		ConsecutiveFailures: s.failures,
+		StaleAfter:          s.cfg.StaleAfter.String(),
+		Stale:               s.lastChecked.IsZero() || s.cfg.Clock.Now().Sub(s.lastChecked) > s.cfg.StaleAfter,
+	}
+	switch {
+	case s.registry == nil:
+		st.State = StatePending
+	case s.fromCache:
+		st.State = StateCached
+	default:
+		st.State = StateLive
+	}
+	if s.registry != nil {
+		st.ClaimCount = len(s.registry.Claims)
+	}
+	if len(s.violations) > 0 {
+		st.Violations = append(registry.Violations(nil), s.violations...)
+	}
+	if s.lastErr != nil {
+		st.LastError = s.lastErr.Error()
+	}
+	return st
+}
@@ -2,35 +2,77 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"net/http"
+	"log/slog"
 	"sync"
 	"time"
 
+	"github.com/stuttgart-things/machinery-registry-api/internal/logging"
+	"github.com/stuttgart-things/machinery-registry-api/internal/metrics"
 	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer returns the package tracer from the current global provider, so
+// that spans follow a provider installed after the package was loaded.
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/stuttgart-things/machinery-registry-api/internal/sync")
+}
+
 // Config holds syncer configuration.
 type Config struct {
+	Name     string        // Registry name used to annotate entries; defaults to Repo or the source
 	Repo     string        // GitHub repo slug, e.g. "stuttgart-things/harvester"
 	Path     string        // Path to registry file in repo
 	Branch   string        // Git branch
 	Token    string        // GitHub token (optional, for private repos)
 	Interval time.Duration // Polling interval
 	BaseURL  string        // Override base URL (for testing); defaults to https://raw.githubusercontent.com
+	Source   Source        // Registry source; defaults to a GitHubSource built from the fields above
+
+	// StaleAfter marks the snapshot stale when no sync has succeeded for
+	// this long; defaults to five polling intervals.
+	StaleAfter time.Duration
+	// MaxBackoff caps the retry delay after consecutive failures; defaults
+	// to ten minutes (or the polling interval, if longer).
+	MaxBackoff time.Duration
+	// Clock drives the polling loop; defaults to the wall clock.
+	Clock Clock
+	// Logger receives sync events; defaults to slog.Default().
+	Logger *slog.Logger
+	// CacheFile, if set, is where every validated snapshot is persisted.
+	// When the initial sync fails, the syncer starts from this file instead
+	// and reports StateCached until a live sync succeeds.
+	CacheFile string
 }
 
-// Syncer periodically fetches registry.yaml from GitHub and maintains
+// Syncer periodically fetches registry.yaml from its Source and maintains
 // a thread-safe in-memory snapshot.
 type Syncer struct {
-	cfg      Config
-	registry *registry.ClaimRegistry
-	mu       sync.RWMutex
-	client   *http.Client
-	cancel   context.CancelFunc
-	done     chan struct{}
+	cfg         Config
+	registry    *registry.ClaimRegistry
+	revision    string
+	hash        string              // content hash of the current snapshot
+	violations  registry.Violations // findings for the most recently fetched content
+	lastChecked time.Time           // last time the source was successfully queried
+	lastChanged time.Time           // last time the snapshot was swapped
+	lastAttempt time.Time           // last time a fetch was started
+	failures    int                 // consecutive failed attempts
+	lastErr     error               // error of the most recent failed attempt
+	nextAttempt time.Time           // when the background loop will fetch next
+	fromCache   bool                // snapshot was loaded from CacheFile, not yet confirmed live
+	jitter      func(time.Duration) time.Duration
+	trigger     chan struct{}
+	onSwap      func(Changeset) // called after a new snapshot was swapped in
+	log         *slog.Logger
+	mu          sync.RWMutex
+	cancel      context.CancelFunc
+	done        chan struct{}
 }
 
 // NewSyncer creates a new Syncer with the given configuration.
@@ -44,101 +86,297 @@ func NewSyncer(cfg Config) *Syncer {
 	if cfg.Interval == 0 {
 		cfg.Interval = 60 * time.Second
 	}
+	if cfg.StaleAfter == 0 {
+		cfg.StaleAfter = 5 * cfg.Interval
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	if cfg.MaxBackoff < cfg.Interval {
+		cfg.MaxBackoff = cfg.Interval
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
 	if cfg.BaseURL == "" {
 		cfg.BaseURL = "https://raw.githubusercontent.com"
 	}
+	if cfg.Source == nil {
+		cfg.Source = NewGitHubSource(cfg)
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.Repo
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.Source.String()
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
 	return &Syncer{
-		cfg:    cfg,
-		client: &http.Client{Timeout: 30 * time.Second},
-		done:   make(chan struct{}),
+		cfg:     cfg,
+		jitter:  equalJitter,
+		trigger: make(chan struct{}, 1),
+		log:     cfg.Logger.With(logging.KeyRegistry, cfg.Name),
+		done:    make(chan struct{}),
 	}
 }
 
-// rawURL returns the GitHub raw content URL for the registry file.
-func (s *Syncer) rawURL() string {
-	return fmt.Sprintf("%s/%s/%s/%s", s.cfg.BaseURL, s.cfg.Repo, s.cfg.Branch, s.cfg.Path)
-}
+// fetch downloads the registry file and swaps in a new snapshot when its
+// content differs from the current one. It reports whether a swap happened.
+func (s *Syncer) fetch(ctx context.Context) (changed bool, err error) {
+	ctx, span := tracer().Start(ctx, "sync.fetch", trace.WithAttributes(
+		attribute.String("registry.name", s.Name()),
+		attribute.String("registry.source", s.cfg.Source.String()),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Bool("registry.changed", changed))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 
-// fetch downloads and parses the registry file.
-func (s *Syncer) fetch(ctx context.Context) (*registry.ClaimRegistry, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.rawURL(), nil)
+	res, err := s.cfg.Source.Fetch(ctx)
+	if errors.Is(err, ErrNotModified) {
+		s.touch()
+		return false, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return false, err
 	}
+	metrics.SyncFetchedBytes.WithLabelValues(s.Name()).Add(float64(len(res.Data)))
 
-	if s.cfg.Token != "" {
-		req.Header.Set("Authorization", "token "+s.cfg.Token)
+	hash := contentHash(res.Data)
+	s.mu.RLock()
+	unchanged := s.registry != nil && hash == s.hash
+	s.mu.RUnlock()
+	if unchanged {
+		s.commit(res)
+		s.touch()
+		return false, nil
 	}
 
-	resp, err := s.client.Do(req)
+	reg, violations, err := registry.Check(res.Data)
 	if err != nil {
-		return nil, fmt.Errorf("fetching registry: %w", err)
+		return false, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, s.rawURL())
+	// Refuse to swap in an invalid registry; the last good snapshot stays.
+	s.mu.Lock()
+	s.violations = violations
+	s.mu.Unlock()
+	if violations.HasErrors() {
+		return false, &registry.ValidationError{Violations: violations}
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	now := s.cfg.Clock.Now()
+	s.mu.Lock()
+	prev, prevRevision := s.registry, s.revision
+	s.registry = reg
+	s.revision = res.Revision
+	s.hash = hash
+	s.lastChecked = now
+	s.lastChanged = now
+	s.mu.Unlock()
+
+	s.commit(res)
+	s.saveCache(res, now)
+
+	if s.onSwap != nil {
+		cs := Changeset{
+			Registry:         s.Name(),
+			Revision:         res.Revision,
+			PreviousRevision: prevRevision,
+			Time:             now,
+		}
+		// The initial load is not a change.
+		if prev != nil {
+			cs.Changes = registry.Diff(prev.Claims, reg.Claims)
+		}
+		s.onSwap(cs)
+	}
+	return true, nil
+}
+
+// commit records that res was accepted: the snapshot is confirmed live, and
+// a conditional source is told about it. Until then the source keeps sending
+// the full registry, so a rejected registry is validated, and reported, on
+// every attempt rather than hidden by a not-modified response.
+func (s *Syncer) commit(res *FetchResult) {
+	s.mu.Lock()
+	s.fromCache = false
+	s.mu.Unlock()
+	if c, ok := s.cfg.Source.(Committer); ok {
+		c.Commit(res)
+	}
+}
+
+// touch records a successful check that did not change the snapshot.
+func (s *Syncer) touch() {
+	s.mu.Lock()
+	s.lastChecked = s.cfg.Clock.Now()
+	s.mu.Unlock()
+}
+
+// attempt runs fetch and records the outcome for Status and metrics.
+func (s *Syncer) attempt(ctx context.Context) (bool, error) {
+	start := time.Now()
+	s.mu.Lock()
+	s.lastAttempt = s.cfg.Clock.Now()
+	s.mu.Unlock()
+
+	changed, err := s.fetch(ctx)
+
+	s.mu.Lock()
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		s.failures++
+		s.lastErr = err
+	} else {
+		s.failures = 0
+		s.lastErr = nil
 	}
+	s.mu.Unlock()
 
-	return registry.ParseData(data)
+	s.observe(start, changed, err)
+	return changed, err
 }
 
-// InitialSync performs the first sync. Returns an error if the fetch fails
-// (fail-fast on startup).
-func (s *Syncer) InitialSync(ctx context.Context) error {
-	reg, err := s.fetch(ctx)
+// observe records the outcome of a sync attempt that started at start.
+func (s *Syncer) observe(start time.Time, changed bool, err error) {
+	name := s.Name()
+	metrics.SyncDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	var invalid *registry.ValidationError
+	result := metrics.ResultUnchanged
+	switch {
+	case errors.As(err, &invalid):
+		result = metrics.ResultInvalid
+	case err != nil:
+		result = metrics.ResultError
+	case changed:
+		result = metrics.ResultChanged
+	}
+	metrics.SyncAttempts.WithLabelValues(name, result).Inc()
+
 	if err != nil {
-		return fmt.Errorf("initial sync failed: %w", err)
+		metrics.SyncFailures.WithLabelValues(name).Inc()
+		return
 	}
+	metrics.SyncLastSuccess.WithLabelValues(name).Set(float64(s.cfg.Clock.Now().Unix()))
+}
+
+// sync performs a single fetch, logs the outcome and schedules the next
+// attempt. It returns the delay until that attempt.
+func (s *Syncer) sync(ctx context.Context) time.Duration {
+	changed, err := s.attempt(ctx)
 
 	s.mu.Lock()
-	s.registry = reg
+	delay := s.nextDelay(s.failures, err)
+	s.nextAttempt = s.cfg.Clock.Now().Add(delay)
 	s.mu.Unlock()
 
-	log.Printf("Initial sync complete: %d claims loaded from %s", len(reg.Claims), s.rawURL())
+	if err != nil {
+		s.log.Warn("sync failed", logging.Err(err), "retryIn", delay.Round(time.Second).String())
+		return delay
+	}
+	if changed {
+		reg, revision := s.GetRegistry(), s.Revision()
+		s.log.Info("sync complete", logging.KeyClaimCount, len(reg.Claims), logging.KeyRevision, revision)
+	} else {
+		s.log.Debug("registry unchanged", logging.KeyRevision, s.Revision())
+	}
+	return delay
+}
+
+// InitialSync performs the first sync. If the fetch fails, the snapshot
+// persisted in CacheFile is served instead; without one an error is returned
+// (fail-fast on startup).
+func (s *Syncer) InitialSync(ctx context.Context) error {
+	if _, err := s.attempt(ctx); err != nil {
+		if s.cfg.CacheFile == "" {
+			return fmt.Errorf("initial sync failed: %w", err)
+		}
+		if cacheErr := s.loadCache(); cacheErr != nil {
+			return fmt.Errorf("initial sync failed: %w (cache: %w)", err, cacheErr)
+		}
+		s.log.Warn("initial sync failed, serving from cache", logging.Err(err),
+			logging.KeyClaimCount, len(s.GetRegistry().Claims),
+			logging.KeyRevision, s.Revision(),
+			"cachedAt", s.LastChanged().Format(time.RFC3339))
+		return nil
+	}
+
+	s.log.Info("initial sync complete",
+		logging.KeyClaimCount, len(s.GetRegistry().Claims),
+		logging.KeyRevision, s.Revision(),
+		"source", s.cfg.Source.String())
 	return nil
 }
 
-// Start begins the background polling loop. Call Stop() or cancel the
-// context to terminate.
+// Start begins the background polling loop. Failed syncs are retried with
+// jittered exponential backoff; Trigger and sources implementing Watcher
+// additionally request an immediate sync. Call Stop() or cancel the context
+// to terminate.
 func (s *Syncer) Start(ctx context.Context) {
 	ctx, s.cancel = context.WithCancel(ctx)
 
+	var changes <-chan struct{}
+	if w, ok := s.cfg.Source.(Watcher); ok {
+		ch, err := w.Watch(ctx)
+		if err != nil {
+			s.log.Warn("watch disabled", "source", s.cfg.Source.String(), logging.Err(err))
+		} else {
+			changes = ch
+		}
+	}
+
+	s.mu.Lock()
+	delay := s.nextDelay(s.failures, s.lastErr)
+	s.nextAttempt = s.cfg.Clock.Now().Add(delay)
+	s.mu.Unlock()
+
 	go func() {
 		defer close(s.done)
-		ticker := time.NewTicker(s.cfg.Interval)
-		defer ticker.Stop()
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				reg, err := s.fetch(ctx)
-				if err != nil {
-					log.Printf("Sync error: %v", err)
-					continue
-				}
-				s.mu.Lock()
-				s.registry = reg
-				s.mu.Unlock()
-				log.Printf("Sync complete: %d claims", len(reg.Claims))
+			case <-s.cfg.Clock.After(delay):
+			case <-changes:
+			case <-s.trigger:
 			}
+			delay = s.sync(ctx)
 		}
 	}()
 }
 
-// Stop terminates the background polling loop and waits for it to finish.
+// Trigger requests an out-of-band sync from the background loop. Requests
+// made while one is already pending are coalesced into it; the return value
+// reports whether a new sync was queued.
+func (s *Syncer) Trigger() bool {
+	select {
+	case s.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop terminates the background polling loop, waits for it to finish and
+// releases the source if it holds resources such as a temporary clone.
 func (s *Syncer) Stop() {
 	if s.cancel != nil {
 		s.cancel()
 		<-s.done
 	}
+	if c, ok := s.cfg.Source.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			s.log.Warn("closing source failed", logging.Err(err))
+		}
+	}
 }
 
 // GetRegistry returns the current registry snapshot (thread-safe).
@@ -147,3 +385,42 @@ func (s *Syncer) GetRegistry() *registry.ClaimRegistry {
 	defer s.mu.RUnlock()
 	return s.registry
 }
+
+// snapshot returns the current registry and its content hash.
+func (s *Syncer) snapshot() (*registry.ClaimRegistry, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registry, s.hash
+}
+
+// Revision returns the source revision of the current snapshot.
+func (s *Syncer) Revision() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// LastChecked returns when the source was last queried successfully,
+// whether or not the registry changed.
+func (s *Syncer) LastChecked() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastChecked
+}
+
+// LastChanged returns when the snapshot was last replaced.
+func (s *Syncer) LastChanged() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastChanged
+}
+
+// Name returns the registry name.
+func (s *Syncer) Name() string {
+	return s.cfg.Name
+}
+
+// Config returns the effective syncer configuration.
+func (s *Syncer) Config() Config {
+	return s.cfg
+}
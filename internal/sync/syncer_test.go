@@ -1,14 +1,27 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/stuttgart-things/machinery-registry-api/internal/metrics"
+	"github.com/stuttgart-things/machinery-registry-api/internal/registry"
 )
 
 const testRegistryYAML = `
@@ -112,10 +125,350 @@ func TestBackgroundSync(t *testing.T) {
 	assert.GreaterOrEqual(t, callCount, 3)
 }
 
+func TestConditionalFetch(t *testing.T) {
+	var requests, notModified int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testRegistryYAML))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+	})
+
+	require.NoError(t, s.InitialSync(context.Background()))
+	first := s.GetRegistry()
+	changed := s.LastChanged()
+	assert.Equal(t, `"v1"`, s.Revision())
+
+	time.Sleep(5 * time.Millisecond)
+	updated, err := s.fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, updated)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+	assert.Same(t, first, s.GetRegistry())
+	assert.Equal(t, changed, s.LastChanged())
+	assert.True(t, s.LastChecked().After(changed))
+}
+
+func TestSkipUnchangedContent(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+	})
+
+	require.NoError(t, s.InitialSync(context.Background()))
+	first := s.GetRegistry()
+
+	// Same content without ETag support: no swap
+	updated, err := s.fetch(context.Background())
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.Same(t, first, s.GetRegistry())
+
+	// Changed content: swap
+	body = testRegistryYAML + `  - name: added
+    template: volumeclaim
+    category: cli
+    status: active
+`
+	updated, err = s.fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Len(t, s.GetRegistry().Claims, 3)
+}
+
+func TestStatusTracksFailures(t *testing.T) {
+	fail := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(testRegistryYAML))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:       "test/repo",
+		BaseURL:    ts.URL,
+		StaleAfter: time.Hour,
+	})
+
+	require.NoError(t, s.InitialSync(context.Background()))
+	st := s.Status()
+	assert.Equal(t, 2, st.ClaimCount)
+	assert.Equal(t, 0, st.ConsecutiveFailures)
+	assert.Empty(t, st.LastError)
+	assert.True(t, st.Ready())
+
+	fail = true
+	s.sync(context.Background())
+	s.sync(context.Background())
+
+	st = s.Status()
+	assert.Equal(t, 2, st.ConsecutiveFailures)
+	assert.Contains(t, st.LastError, "unexpected status 502")
+	assert.True(t, st.LastAttempt.After(st.LastSuccess))
+	assert.Equal(t, 2, st.ClaimCount, "last good snapshot is kept")
+	assert.True(t, st.Ready(), "not stale yet")
+
+	fail = false
+	s.sync(context.Background())
+
+	st = s.Status()
+	assert.Equal(t, 0, st.ConsecutiveFailures)
+	assert.Empty(t, st.LastError)
+}
+
+func TestStatusStale(t *testing.T) {
+	ts := newTestServer(t, testRegistryYAML, http.StatusOK)
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:       "test/repo",
+		BaseURL:    ts.URL,
+		StaleAfter: time.Millisecond,
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	time.Sleep(5 * time.Millisecond)
+	st := s.Status()
+	assert.True(t, st.Stale)
+	assert.False(t, st.Ready())
+}
+
+func TestTriggerCoalesces(t *testing.T) {
+	fetched := make(chan struct{}, 8)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testRegistryYAML))
+		fetched <- struct{}{}
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:     "test/repo",
+		BaseURL:  ts.URL,
+		Interval: time.Hour,
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+	<-fetched
+
+	assert.True(t, s.Trigger())
+	assert.False(t, s.Trigger(), "second trigger coalesces into the pending one")
+
+	s.Start(context.Background())
+	defer s.Stop()
+
+	select {
+	case <-fetched:
+	case <-time.After(2 * time.Second):
+		t.Fatal("trigger did not cause a sync")
+	}
+
+	select {
+	case <-fetched:
+		t.Fatal("coalesced trigger caused a second sync")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInvalidRegistryKeepsLastGood(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+	good := s.GetRegistry()
+
+	body = testRegistryYAML + `  - name: hacky
+    template: volumeclaim
+    category: cli
+    status: archived
+`
+	_, err := s.attempt(context.Background())
+
+	var verr *registry.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Same(t, good, s.GetRegistry())
+
+	st := s.Status()
+	assert.Equal(t, 1, st.ConsecutiveFailures)
+	assert.True(t, st.Violations.HasErrors())
+	assert.Contains(t, st.LastError, "registry validation failed")
+}
+
+func TestRejectedRegistryNotHiddenByNotModified(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + contentHash([]byte(body)) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	body = "apiVersion: v1\nkind: ConfigMap\n"
+	for range 2 {
+		_, err := s.attempt(context.Background())
+		assert.Error(t, err, "the rejected registry is fetched and validated again")
+	}
+	assert.Equal(t, 2, s.Status().ConsecutiveFailures)
+
+	body = testRegistryYAML
+	_, err := s.attempt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.Status().ConsecutiveFailures)
+}
+
+func TestInitialSyncInvalid(t *testing.T) {
+	ts := newTestServer(t, "apiVersion: v1\nkind: ConfigMap\n", http.StatusOK)
+	defer ts.Close()
+
+	s := NewSyncer(Config{
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+	})
+
+	err := s.InitialSync(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, s.GetRegistry())
+}
+
+func TestSyncMetrics(t *testing.T) {
+	body := testRegistryYAML
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	s := NewSyncer(Config{Name: "metrics-test", Repo: "test/repo", BaseURL: ts.URL})
+	counters := func() map[string]float64 {
+		return map[string]float64{
+			metrics.ResultChanged:   testutil.ToFloat64(metrics.SyncAttempts.WithLabelValues("metrics-test", metrics.ResultChanged)),
+			metrics.ResultUnchanged: testutil.ToFloat64(metrics.SyncAttempts.WithLabelValues("metrics-test", metrics.ResultUnchanged)),
+			metrics.ResultInvalid:   testutil.ToFloat64(metrics.SyncAttempts.WithLabelValues("metrics-test", metrics.ResultInvalid)),
+			"failures":              testutil.ToFloat64(metrics.SyncFailures.WithLabelValues("metrics-test")),
+			"bytes":                 testutil.ToFloat64(metrics.SyncFetchedBytes.WithLabelValues("metrics-test")),
+		}
+	}
+	before := counters()
+
+	require.NoError(t, s.InitialSync(context.Background()))
+	s.attempt(context.Background())
+	body = "apiVersion: v1\nkind: ConfigMap\n"
+	s.attempt(context.Background())
+
+	// The counters are process-wide; compare deltas so repeated runs pass.
+	after := counters()
+	assert.Equal(t, 1.0, after[metrics.ResultChanged]-before[metrics.ResultChanged])
+	assert.Equal(t, 1.0, after[metrics.ResultUnchanged]-before[metrics.ResultUnchanged])
+	assert.Equal(t, 1.0, after[metrics.ResultInvalid]-before[metrics.ResultInvalid])
+	assert.Equal(t, 1.0, after["failures"]-before["failures"])
+	assert.Equal(t, float64(2*len(testRegistryYAML)+len(body)), after["bytes"]-before["bytes"])
+	assert.Positive(t, testutil.ToFloat64(metrics.SyncLastSuccess.WithLabelValues("metrics-test")))
+}
+
 func TestDefaultConfig(t *testing.T) {
 	s := NewSyncer(Config{Repo: "test/repo"})
 	assert.Equal(t, "claims/registry.yaml", s.cfg.Path)
 	assert.Equal(t, "main", s.cfg.Branch)
 	assert.Equal(t, 60*time.Second, s.cfg.Interval)
+	assert.Equal(t, 5*time.Minute, s.cfg.StaleAfter)
 	assert.Equal(t, "https://raw.githubusercontent.com", s.cfg.BaseURL)
 }
+
+func TestFetchSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ts := newTestServer(t, testRegistryYAML, http.StatusOK)
+	defer ts.Close()
+
+	s := NewSyncer(Config{Name: "trace-test", Repo: "test/repo", BaseURL: ts.URL})
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	var fetch, client sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindInternal:
+			fetch = span
+		case trace.SpanKindClient:
+			client = span
+		}
+	}
+	require.NotNil(t, fetch)
+	require.NotNil(t, client, "HTTP client span")
+	assert.Equal(t, "sync.fetch", fetch.Name())
+	assert.Contains(t, fetch.Attributes(), attribute.String("registry.name", "trace-test"))
+	assert.Contains(t, fetch.Attributes(), attribute.Bool("registry.changed", true))
+	assert.Equal(t, fetch.SpanContext().SpanID(), client.Parent().SpanID())
+
+	ts.Close()
+	s.attempt(context.Background())
+	failed := recorder.Ended()[len(recorder.Ended())-1]
+	assert.Equal(t, "sync.fetch", failed.Name())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
+
+func TestSyncLogging(t *testing.T) {
+	ts := newTestServer(t, testRegistryYAML, http.StatusOK)
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	s := NewSyncer(Config{
+		Name:    "log-test",
+		Repo:    "test/repo",
+		BaseURL: ts.URL,
+		Logger:  slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+	require.NoError(t, s.InitialSync(context.Background()))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "initial sync complete", entry["msg"])
+	assert.Equal(t, "log-test", entry["registry"])
+	assert.Equal(t, float64(2), entry["claimCount"])
+	assert.NotEmpty(t, entry["revision"])
+
+	buf.Reset()
+	ts.Close()
+	s.sync(context.Background())
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "sync failed", entry["msg"])
+	assert.NotEmpty(t, entry["error"])
+}